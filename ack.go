@@ -0,0 +1,76 @@
+package goob
+
+import "context"
+
+// AckSubscription is returned by SubscribeAckN
+type AckSubscription struct {
+	// Events is closed once the subscription ends
+	Events <-chan Event
+
+	acks chan struct{}
+	done chan struct{}
+}
+
+// SubscribeAckN is like Subscribe, but at most maxInFlight received events can be unacknowledged, the next event
+// is held back in the buffer until Ack is called, so a slow consumer applies backpressure per event rather than
+// per buffer. A maxInFlight below 1 is treated as 1. It unsubscribes when ctx is done.
+func (ob *Observable) SubscribeAckN(ctx context.Context, maxInFlight int) *AckSubscription {
+	if maxInFlight < 1 {
+		maxInFlight = 1
+	}
+
+	s := ob.subscribe(ctx, &subscriber{})
+	events := make(chan Event)
+	a := &AckSubscription{Events: events, acks: make(chan struct{}), done: make(chan struct{})}
+
+	go func() {
+		defer trackGoroutine()()
+		defer close(a.done)
+		defer close(events)
+
+		inFlight := 0
+		var pending Event
+		has := false
+
+		for {
+			var in <-chan Event
+			var out chan<- Event
+			if has {
+				out = events
+			} else if inFlight < maxInFlight {
+				in = s
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+
+			case e, ok := <-in:
+				if !ok {
+					return
+				}
+				pending, has = e, true
+
+			case out <- pending:
+				pending, has = nil, false
+				inFlight++
+
+			case <-a.acks:
+				if inFlight > 0 {
+					inFlight--
+				}
+			}
+		}
+	}()
+
+	return a
+}
+
+// Ack acknowledges the oldest unacknowledged event, so that one more event can be received.
+// The extra calls are ignored.
+func (a *AckSubscription) Ack() {
+	select {
+	case a.acks <- struct{}{}:
+	case <-a.done:
+	}
+}
@@ -0,0 +1,48 @@
+package goob_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ysmood/goob"
+)
+
+func TestSubscribeAckN(t *testing.T) {
+	checkLeak(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ob := goob.New()
+	defer ob.Close()
+
+	a := ob.SubscribeAckN(ctx, 2)
+	for i := 0; i < 5; i++ {
+		ob.Publish(i)
+	}
+
+	eq(t, 0, <-a.Events)
+	eq(t, 1, <-a.Events)
+
+	paused := func() {
+		settle()
+		select {
+		case e := <-a.Events:
+			t.Fatal("should wait for an ack", e)
+		default:
+		}
+	}
+	paused()
+
+	a.Ack()
+	eq(t, 2, <-a.Events)
+	paused()
+
+	a.Ack()
+	a.Ack()
+	eq(t, 3, <-a.Events)
+	eq(t, 4, <-a.Events)
+
+	cancel()
+	for range a.Events {
+	}
+	a.Ack()
+}
@@ -0,0 +1,201 @@
+package goob
+
+import (
+	"context"
+	"time"
+)
+
+// BufferWhen collects the events of source and emits them as a []Event each time trigger emits.
+// The emitted slice may be empty. When either source or trigger completes the collected events, if any,
+// are emitted and the returned observable completes. It's closed when ctx is done.
+func BufferWhen(ctx context.Context, source, trigger *Observable) *Observable {
+	return source.derive(func(out *Observable) {
+		s := source.follow()
+		t := trigger.follow()
+
+		go func() {
+			defer trackGoroutine()()
+			defer source.Unsubscribe(s)
+			defer trigger.Unsubscribe(t)
+
+			buf := []Event{}
+			complete := func() {
+				if len(buf) > 0 {
+					out.Publish(buf)
+				}
+				out.CloseDrain()
+			}
+
+			for {
+				select {
+				case <-ctx.Done():
+					out.Close()
+					return
+				case e, ok := <-s:
+					if !ok {
+						complete()
+						return
+					}
+					buf = append(buf, e)
+				case _, ok := <-t:
+					if !ok {
+						complete()
+						return
+					}
+					out.Publish(buf)
+					buf = []Event{}
+				}
+			}
+		}()
+	})
+}
+
+// SlidingWindow emits the last n events of ob as a []Event on each event of ob.
+// If partial is true the windows before the first n events are also emitted, otherwise they are skipped.
+// n < 1 is treated as 1.
+func SlidingWindow(ctx context.Context, ob *Observable, n int, partial bool) *Observable {
+	if n < 1 {
+		n = 1
+	}
+
+	window := make([]Event, 0, n)
+
+	return ob.operate(ctx, func(out *Observable, e Event) bool {
+		if len(window) == n {
+			window = window[1:]
+		}
+		window = append(window, e)
+
+		if partial || len(window) == n {
+			out.Publish(append([]Event{}, window...))
+		}
+		return true
+	})
+}
+
+// BufferToggle starts a buffer of the events of source on each event of openings, the buffer is emitted as a []Event
+// once the observable that closing returns for the opening event emits or completes.
+// A nil closing observable keeps its buffer open until source completes.
+// Buffers can overlap. When source completes the open buffers are emitted.
+func BufferToggle(ctx context.Context, source, openings *Observable, closing func(Event) *Observable) *Observable {
+	return source.derive(func(out *Observable) {
+		s := source.follow()
+		o := openings.follow()
+		guard := supervise(ctx)
+
+		go func() {
+			defer trackGoroutine()()
+			defer guard(out)
+			done := make(chan struct{})
+			closes := make(chan int)
+			buffers := map[int][]Event{}
+			order := []int{}
+			id := 0
+
+			defer func() {
+				close(done)
+				source.Unsubscribe(s)
+				openings.Unsubscribe(o)
+			}()
+
+			watch := func(id int, ob *Observable) {
+				defer trackGoroutine()()
+				c := ob.follow()
+				defer ob.Unsubscribe(c)
+
+				select {
+				case <-done:
+					return
+				case <-c:
+				}
+
+				select {
+				case <-done:
+				case closes <- id:
+				}
+			}
+
+			for {
+				select {
+				case <-ctx.Done():
+					out.Close()
+					return
+
+				case e, ok := <-s:
+					if !ok {
+						for _, id := range order {
+							out.Publish(buffers[id])
+						}
+						out.CloseDrain()
+						return
+					}
+					for _, id := range order {
+						buffers[id] = append(buffers[id], e)
+					}
+
+				case e, ok := <-o:
+					if !ok {
+						o = nil
+						continue
+					}
+					id++
+					buffers[id] = []Event{}
+					order = append(order, id)
+					if c := closing(e); c != nil {
+						go watch(id, c)
+					}
+
+				case id := <-closes:
+					out.Publish(buffers[id])
+					delete(buffers, id)
+					for i, oid := range order {
+						if oid == id {
+							order = append(order[:i], order[i+1:]...)
+							break
+						}
+					}
+				}
+			}
+		}()
+	})
+}
+
+// BufferIdle collects the events of ob and emits them as a []Event once d passes without any new event,
+// then starts a new buffer. When ob completes the collected events, if any, are emitted.
+func (ob *Observable) BufferIdle(ctx context.Context, d time.Duration) *Observable {
+	return ob.derive(func(out *Observable) {
+		s := ob.follow()
+
+		go func() {
+			defer trackGoroutine()()
+			defer ob.Unsubscribe(s)
+
+			var timer <-chan time.Time
+			buf := []Event{}
+
+			for {
+				select {
+				case <-ctx.Done():
+					out.Close()
+					return
+
+				case e, ok := <-s:
+					if !ok {
+						if len(buf) > 0 {
+							out.Publish(buf)
+						}
+						out.CloseDrain()
+						return
+					}
+					buf = append(buf, e)
+					timer = ob.clock.After(d)
+
+				case <-timer:
+					timer = nil
+					out.Publish(buf)
+					buf = []Event{}
+				}
+			}
+		}()
+	})
+}
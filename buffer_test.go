@@ -0,0 +1,208 @@
+package goob_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ysmood/goob"
+)
+
+func TestBufferWhen(t *testing.T) {
+	checkLeak(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	source := goob.New()
+	trigger := goob.New()
+	s := goob.BufferWhen(ctx, source, trigger).Subscribe()
+
+	flush := func() goob.Event {
+		time.Sleep(10 * time.Millisecond)
+		trigger.Publish(nil)
+		return <-s
+	}
+
+	source.Publish(1)
+	source.Publish(2)
+	eq(t, []goob.Event{1, 2}, flush())
+
+	eq(t, []goob.Event{}, flush())
+
+	source.Publish(3)
+	eq(t, []goob.Event{3}, flush())
+}
+
+func TestBufferWhenComplete(t *testing.T) {
+	checkLeak(t)
+
+	ctx := context.Background()
+
+	// the emitted batch is kept when trigger completes right after it
+	source := goob.New()
+	trigger := goob.New()
+	s := goob.BufferWhen(ctx, source, trigger).Subscribe()
+
+	source.Publish(1)
+	time.Sleep(10 * time.Millisecond)
+	trigger.Publish(nil)
+	trigger.CloseDrain()
+	eq(t, []goob.Event{[]goob.Event{1}}, collect(s))
+
+	// the collected events are emitted when source completes
+	source = goob.New()
+	trigger = goob.New()
+	s = goob.BufferWhen(ctx, source, trigger).Subscribe()
+
+	source.Publish(2)
+	source.CloseDrain()
+	eq(t, []goob.Event{[]goob.Event{2}}, collect(s))
+	trigger.Close()
+}
+
+func TestBufferWhenLazy(t *testing.T) {
+	checkLeak(t)
+
+	source := goob.FromSlice([]goob.Event{1, 2})
+	trigger := goob.New()
+	defer trigger.Close()
+
+	ob := goob.BufferWhen(context.Background(), source, trigger)
+	time.Sleep(10 * time.Millisecond)
+
+	eq(t, []goob.Event{[]goob.Event{1, 2}}, collect(ob.Subscribe()))
+}
+
+func TestBufferWhenCancel(t *testing.T) {
+	checkLeak(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	source := goob.New()
+	trigger := goob.New()
+	s := goob.BufferWhen(ctx, source, trigger).Subscribe()
+
+	cancel()
+
+	_, ok := <-s
+	eq(t, false, ok)
+
+	time.Sleep(10 * time.Millisecond)
+	eq(t, 0, source.Len())
+	eq(t, 0, trigger.Len())
+}
+
+func TestSlidingWindow(t *testing.T) {
+	checkLeak(t)
+
+	ctx := context.Background()
+	ob := goob.FromSlice([]goob.Event{1, 2, 3, 4})
+
+	eq(t, []goob.Event{
+		[]goob.Event{1},
+		[]goob.Event{1, 2},
+		[]goob.Event{1, 2, 3},
+		[]goob.Event{2, 3, 4},
+	}, collect(goob.SlidingWindow(ctx, ob, 3, true).Subscribe()))
+
+	eq(t, []goob.Event{
+		[]goob.Event{1, 2, 3},
+		[]goob.Event{2, 3, 4},
+	}, collect(goob.SlidingWindow(ctx, ob, 3, false).Subscribe()))
+}
+
+func TestSlidingWindowInvalidSize(t *testing.T) {
+	checkLeak(t)
+
+	ctx := context.Background()
+	ob := goob.FromSlice([]goob.Event{1, 2})
+
+	for _, n := range []int{0, -1} {
+		eq(t, []goob.Event{
+			[]goob.Event{1},
+			[]goob.Event{2},
+		}, collect(goob.SlidingWindow(ctx, ob, n, false).Subscribe()))
+	}
+}
+
+func TestBufferToggle(t *testing.T) {
+	checkLeak(t)
+
+	ctx := context.Background()
+
+	source := goob.New()
+	openings := goob.New()
+	closings := map[goob.Event]*goob.Observable{"a": goob.New(), "b": goob.New()}
+
+	s := goob.BufferToggle(ctx, source, openings, func(e goob.Event) *goob.Observable {
+		return closings[e]
+	}).Subscribe()
+
+	step := func(ob *goob.Observable, e goob.Event) {
+		ob.Publish(e)
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	step(source, 0)
+	step(openings, "a")
+	step(source, 1)
+	step(openings, "b")
+	step(source, 2)
+	step(closings["a"], nil)
+	eq(t, []goob.Event{1, 2}, <-s)
+
+	step(source, 3)
+	step(openings, "a")
+	step(source, 4)
+	step(closings["b"], nil)
+	eq(t, []goob.Event{2, 3, 4}, <-s)
+
+	source.CloseDrain()
+	eq(t, []goob.Event{[]goob.Event{4}}, collect(s))
+}
+
+func TestBufferIdle(t *testing.T) {
+	checkLeak(t)
+
+	clock := goob.NewTestClock()
+	ob := goob.New(goob.WithClock(clock))
+	s := ob.BufferIdle(context.Background(), 10*time.Millisecond).Subscribe()
+
+	ob.Publish(1)
+	clock.BlockUntil(1)
+	clock.Advance(5 * time.Millisecond)
+	ob.Publish(2)
+	clock.BlockUntil(2)
+	clock.Advance(10 * time.Millisecond)
+	eq(t, []goob.Event{1, 2}, <-s)
+
+	ob.Publish(3)
+	clock.BlockUntil(1)
+	clock.Advance(10 * time.Millisecond)
+	eq(t, []goob.Event{3}, <-s)
+
+	ob.Publish(4)
+	ob.CloseDrain()
+	eq(t, []goob.Event{[]goob.Event{4}}, collect(s))
+}
+
+func TestBufferToggleNilClosing(t *testing.T) {
+	checkLeak(t)
+
+	source := goob.New()
+	openings := goob.New()
+	defer openings.Close()
+
+	s := goob.BufferToggle(context.Background(), source, openings, func(goob.Event) *goob.Observable {
+		return nil
+	}).Subscribe()
+
+	openings.Publish("a")
+	settle()
+	source.Publish(1)
+	source.Publish(2)
+	source.CloseDrain()
+
+	eq(t, []goob.Event{[]goob.Event{1, 2}}, collect(s))
+}
@@ -0,0 +1,129 @@
+package goob
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Bus of named topics, each topic is an Observable. A topic is created on its first subscriber and removed
+// once its last subscriber leaves, so dynamic topic names don't accumulate.
+type Bus struct {
+	lock   *sync.Mutex
+	topics map[string]*Observable
+
+	// patterns receives all the events as TopicEvent
+	patterns *Observable
+}
+
+// TopicEvent is the event received via Bus.SubscribePattern
+type TopicEvent struct {
+	Topic string
+	Value Event
+}
+
+// NewBus instance
+func NewBus() *Bus {
+	return &Bus{
+		lock:     &sync.Mutex{},
+		topics:   map[string]*Observable{},
+		patterns: New(),
+	}
+}
+
+// topic returns the observable of the topic, nil if it has no subscriber
+func (bus *Bus) topic(name string) *Observable {
+	bus.lock.Lock()
+	defer bus.lock.Unlock()
+	return bus.topics[name]
+}
+
+// release removes the topic if ob is still its observable and has no subscriber
+func (bus *Bus) release(name string, ob *Observable) {
+	bus.lock.Lock()
+	defer bus.lock.Unlock()
+
+	if bus.topics[name] == ob && ob.Len() == 0 {
+		delete(bus.topics, name)
+	}
+}
+
+// Publish e to the subscribers of the topic
+func (bus *Bus) Publish(topic string, e Event) {
+	if ob := bus.topic(topic); ob != nil {
+		ob.Publish(e)
+	}
+
+	if bus.patterns.Len() > 0 {
+		bus.patterns.Publish(TopicEvent{topic, e})
+	}
+}
+
+// Subscribe the events of the topic until ctx is done
+func (bus *Bus) Subscribe(ctx context.Context, topic string) <-chan Event {
+	bus.lock.Lock()
+	defer bus.lock.Unlock()
+
+	// subscribe under the lock, so release can't remove the topic in between
+	ob, has := bus.topics[topic]
+	if !has {
+		var created *Observable
+		created = New(OnUnsubscribe(func(uint64, error) { bus.release(topic, created) }))
+		ob = created
+		bus.topics[topic] = ob
+	}
+	return ob.subscribe(ctx, &subscriber{})
+}
+
+// Count of the subscribers of the topic
+func (bus *Bus) Count(topic string) int {
+	if ob := bus.topic(topic); ob != nil {
+		return ob.Len()
+	}
+	return 0
+}
+
+// Topics that have subscribers, sorted
+func (bus *Bus) Topics() []string {
+	bus.lock.Lock()
+	defer bus.lock.Unlock()
+
+	list := make([]string, 0, len(bus.topics))
+	for name := range bus.topics {
+		list = append(list, name)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// SubscribePattern subscribes the events of all the topics that match the pattern until ctx is done,
+// each event is a TopicEvent. Topics are dot-separated segments, in the pattern "*" matches exactly
+// one segment and "#" matches zero or more segments, such as "orders.*" or "orders.#".
+func (bus *Bus) SubscribePattern(ctx context.Context, pattern string) <-chan Event {
+	segments := strings.Split(pattern, ".")
+
+	return bus.patterns.subscribe(ctx, &subscriber{filter: func(e Event) bool {
+		return matchTopic(segments, strings.Split(e.(TopicEvent).Topic, "."))
+	}})
+}
+
+func matchTopic(pattern, topic []string) bool {
+	if len(pattern) == 0 {
+		return len(topic) == 0
+	}
+
+	switch pattern[0] {
+	case "#":
+		for i := 0; i <= len(topic); i++ {
+			if matchTopic(pattern[1:], topic[i:]) {
+				return true
+			}
+		}
+		return false
+	case "*":
+		return len(topic) > 0 && matchTopic(pattern[1:], topic[1:])
+	default:
+		return len(topic) > 0 && pattern[0] == topic[0] && matchTopic(pattern[1:], topic[1:])
+	}
+}
@@ -0,0 +1,113 @@
+package goob_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ysmood/goob"
+)
+
+func TestBus(t *testing.T) {
+	checkLeak(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	bus := goob.NewBus()
+	a := bus.Subscribe(ctx, "a")
+	b := bus.Subscribe(ctx, "b")
+	bus.Subscribe(ctx, "b")
+
+	eq(t, 1, bus.Count("a"))
+	eq(t, 2, bus.Count("b"))
+	eq(t, 0, bus.Count("c"))
+
+	bus.Publish("a", 1)
+	bus.Publish("b", 2)
+	bus.Publish("c", 3)
+	bus.Publish("a", 4)
+
+	eq(t, 1, <-a)
+	eq(t, 4, <-a)
+	eq(t, 2, <-b)
+
+	cancel()
+	for range a {
+	}
+	eq(t, 0, bus.Count("a"))
+}
+
+func TestBusReleaseTopic(t *testing.T) {
+	checkLeak(t)
+
+	bus := goob.NewBus()
+
+	for i := 0; i < 10; i++ {
+		ctx, cancel := context.WithCancel(context.Background())
+		s := bus.Subscribe(ctx, fmt.Sprintf("user.%d", i))
+		cancel()
+		for range s {
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	bus.Subscribe(ctx, "keep")
+
+	for i := 0; len(bus.Topics()) > 1; i++ {
+		if i == 100 {
+			t.Fatal("topics not released", bus.Topics())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	eq(t, []string{"keep"}, bus.Topics())
+
+	// the topic is created again for a new subscriber
+	s := bus.Subscribe(ctx, "user.0")
+	bus.Publish("user.0", 1)
+	eq(t, 1, <-s)
+}
+
+func TestBusSubscribePattern(t *testing.T) {
+	checkLeak(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bus := goob.NewBus()
+	one := bus.SubscribePattern(ctx, "orders.*")
+	all := bus.SubscribePattern(ctx, "orders.#")
+	mid := bus.SubscribePattern(ctx, "*.eu.#")
+
+	bus.Publish("orders.created", 1)
+	bus.Publish("orders.eu.created", 2)
+	bus.Publish("users.created", 3)
+	bus.Publish("orders", 4)
+	bus.Publish("users.eu", 5)
+
+	expect := func(s <-chan goob.Event, list ...goob.TopicEvent) {
+		t.Helper()
+		for _, e := range list {
+			eq(t, e, <-s)
+		}
+		select {
+		case e := <-s:
+			t.Error("unexpected", e)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	expect(one,
+		goob.TopicEvent{Topic: "orders.created", Value: 1},
+	)
+	expect(all,
+		goob.TopicEvent{Topic: "orders.created", Value: 1},
+		goob.TopicEvent{Topic: "orders.eu.created", Value: 2},
+		goob.TopicEvent{Topic: "orders", Value: 4},
+	)
+	expect(mid,
+		goob.TopicEvent{Topic: "orders.eu.created", Value: 2},
+		goob.TopicEvent{Topic: "users.eu", Value: 5},
+	)
+}
@@ -0,0 +1,172 @@
+package goob
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock that the time-based operators use, see WithClock
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker of a Clock
+type Ticker interface {
+	Chan() <-chan time.Time
+	Stop()
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+type realTicker struct {
+	*time.Ticker
+}
+
+func (t realTicker) Chan() <-chan time.Time {
+	return t.C
+}
+
+// TestClock is a Clock that only moves when Advance is called, it's useful to test time-based operators
+type TestClock struct {
+	lock    *sync.Mutex
+	now     time.Time
+	timers  []*testTimer
+	changed chan struct{}
+}
+
+type testTimer struct {
+	clock  *TestClock
+	at     time.Time
+	period time.Duration
+	c      chan time.Time
+}
+
+// NewTestClock instance
+func NewTestClock() *TestClock {
+	return &TestClock{
+		lock:    &sync.Mutex{},
+		now:     time.Unix(0, 0),
+		changed: make(chan struct{}),
+	}
+}
+
+// Now of the clock
+func (c *TestClock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.now
+}
+
+// After returns a channel that receives the clock's time once the clock is advanced by d
+func (c *TestClock) After(d time.Duration) <-chan time.Time {
+	return c.add(d, 0).c
+}
+
+// NewTicker returns a ticker that ticks each time the clock is advanced by d
+func (c *TestClock) NewTicker(d time.Duration) Ticker {
+	return c.add(d, d)
+}
+
+// Advance the clock by d and fire the timers that are due, in order
+func (c *TestClock) Advance(d time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	end := c.now.Add(d)
+
+	for {
+		var next *testTimer
+		for _, t := range c.timers {
+			if !t.at.After(end) && (next == nil || t.at.Before(next.at)) {
+				next = t
+			}
+		}
+		if next == nil {
+			break
+		}
+
+		c.now = next.at
+		select {
+		case next.c <- c.now:
+		default:
+		}
+
+		if next.period > 0 {
+			next.at = next.at.Add(next.period)
+		} else {
+			c.remove(next)
+		}
+	}
+
+	c.now = end
+}
+
+// BlockUntil blocks until there are at least n timers or tickers waiting on the clock.
+// A timer of After counts from the call until Advance passes its time, even if nothing receives from it any more,
+// a ticker counts until it's stopped. For example Debounce calls After for each event it receives without stopping
+// the previous timer, so after publishing 2 events without advancing past the first timer, BlockUntil(2) returns
+// once Debounce has received the second one.
+func (c *TestClock) BlockUntil(n int) {
+	for {
+		c.lock.Lock()
+		count := len(c.timers)
+		changed := c.changed
+		c.lock.Unlock()
+
+		if count >= n {
+			return
+		}
+		<-changed
+	}
+}
+
+func (c *TestClock) add(d, period time.Duration) *testTimer {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	t := &testTimer{clock: c, at: c.now.Add(d), period: period, c: make(chan time.Time, 1)}
+
+	if d <= 0 && period <= 0 {
+		t.c <- c.now
+		return t
+	}
+
+	c.timers = append(c.timers, t)
+	close(c.changed)
+	c.changed = make(chan struct{})
+
+	return t
+}
+
+func (c *TestClock) remove(t *testTimer) {
+	for i, item := range c.timers {
+		if item == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			return
+		}
+	}
+}
+
+func (t *testTimer) Chan() <-chan time.Time {
+	return t.c
+}
+
+func (t *testTimer) Stop() {
+	t.clock.lock.Lock()
+	defer t.clock.lock.Unlock()
+	t.clock.remove(t)
+}
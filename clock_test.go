@@ -0,0 +1,32 @@
+package goob_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ysmood/goob"
+)
+
+func TestTestClock(t *testing.T) {
+	checkLeak(t)
+
+	clock := goob.NewTestClock()
+	start := clock.Now()
+
+	a := clock.After(10 * time.Second)
+	b := clock.After(5 * time.Second)
+	ticker := clock.NewTicker(3 * time.Second)
+	defer ticker.Stop()
+
+	clock.BlockUntil(3)
+	clock.Advance(4 * time.Second)
+	eq(t, start.Add(3*time.Second), <-ticker.Chan())
+	eq(t, start.Add(4*time.Second), clock.Now())
+
+	clock.Advance(6 * time.Second)
+	eq(t, start.Add(5*time.Second), <-b)
+	eq(t, start.Add(10*time.Second), <-a)
+	eq(t, start.Add(6*time.Second), <-ticker.Chan())
+
+	eq(t, start, <-goob.NewTestClock().After(0))
+}
@@ -0,0 +1,329 @@
+package goob
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrEmpty is returned when an observable completes without emitting any event
+var ErrEmpty = errors.New("goob: observable completed without any event")
+
+// ForkJoin waits for all the observables to complete and returns the last event of each of them,
+// in the same order as obs. It returns ErrEmpty if any of them completes without emitting,
+// or ctx.Err() if ctx is done first.
+func ForkJoin(ctx context.Context, obs ...*Observable) ([]Event, error) {
+	subs := make([]Subscriber, len(obs))
+	for i, ob := range obs {
+		subs[i] = ob.follow()
+	}
+	defer func() {
+		for i, ob := range obs {
+			ob.Unsubscribe(subs[i])
+		}
+	}()
+
+	list := make([]Event, len(obs))
+	for i, s := range subs {
+		emitted := false
+	loop:
+		for {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case e, ok := <-s:
+				if !ok {
+					break loop
+				}
+				list[i] = e
+				emitted = true
+			}
+		}
+
+		if !emitted {
+			return nil, ErrEmpty
+		}
+	}
+
+	return list, nil
+}
+
+// Labeled event with the label of its source
+type Labeled struct {
+	Source string
+	Value  Event
+}
+
+// MergeLabeled emits the events of all the sources as Labeled, with the map key as the label.
+// The returned observable completes once all the sources complete, or is closed once ctx is done.
+func MergeLabeled(ctx context.Context, sources map[string]*Observable) *Observable {
+	return lazy(func(out *Observable) {
+		wg := sync.WaitGroup{}
+		wg.Add(len(sources))
+
+		for label, ob := range sources {
+			label, ob, s := label, ob, ob.follow()
+
+			go func() {
+				defer trackGoroutine()()
+				defer wg.Done()
+				defer ob.Unsubscribe(s)
+
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case e, ok := <-s:
+						if !ok {
+							return
+						}
+						out.Publish(Labeled{label, e})
+					}
+				}
+			}()
+		}
+
+		go func() {
+			defer trackGoroutine()()
+			wg.Wait()
+
+			if ctx.Err() == nil {
+				out.CloseDrain()
+			} else {
+				out.Close()
+			}
+		}()
+	})
+}
+
+// indexed event from the ith observable of fanIn, done means the observable completed
+type indexed struct {
+	i    int
+	e    Event
+	done bool
+}
+
+// fanIn forwards the events of obs to the returned channel until ctx is done,
+// the channel is closed once all obs complete.
+func fanIn(ctx context.Context, obs []*Observable) <-chan indexed {
+	c := make(chan indexed)
+	wg := sync.WaitGroup{}
+	wg.Add(len(obs))
+
+	for i, ob := range obs {
+		i, ob, s := i, ob, ob.follow()
+
+		go func() {
+			defer trackGoroutine()()
+			defer wg.Done()
+			defer ob.Unsubscribe(s)
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case e, ok := <-s:
+					select {
+					case <-ctx.Done():
+						return
+					case c <- indexed{i, e, !ok}:
+					}
+					if !ok {
+						return
+					}
+				}
+			}
+		}()
+	}
+
+	go func() {
+		defer trackGoroutine()()
+		wg.Wait()
+		close(c)
+	}()
+
+	return c
+}
+
+// CombineLatestN emits the result of combine with the latest events of all obs, in the same order as obs,
+// each time any of them emits, once all of them emitted at least once.
+// The returned observable completes once all obs complete, or any of them completes without emitting.
+func CombineLatestN(ctx context.Context, combine func([]Event) Event, obs ...*Observable) *Observable {
+	return lazy(func(out *Observable) {
+		ctx, cancel := context.WithCancel(ctx)
+		c := fanIn(ctx, obs)
+		guard := supervise(ctx)
+
+		go func() {
+			defer trackGoroutine()()
+			defer guard(out)
+			defer cancel()
+
+			latest := make([]Event, len(obs))
+			emitted := make([]bool, len(obs))
+			count := 0
+
+			for {
+				select {
+				case <-ctx.Done():
+					out.Close()
+					return
+
+				case ie, ok := <-c:
+					if !ok {
+						out.CloseDrain()
+						return
+					}
+
+					if ie.done {
+						if !emitted[ie.i] {
+							out.CloseDrain()
+							return
+						}
+						continue
+					}
+
+					if !emitted[ie.i] {
+						emitted[ie.i] = true
+						count++
+					}
+					latest[ie.i] = ie.e
+
+					if count == len(obs) {
+						out.Publish(combine(append([]Event{}, latest...)))
+					}
+				}
+			}
+		}()
+	})
+}
+
+// ZipBuffer is the max number of unpaired events ZipN buffers per source
+const ZipBuffer = 1024
+
+// ErrZipOverflow is emitted by ZipN when a source has more than ZipBuffer unpaired events
+var ErrZipOverflow = errors.New("goob: too many unpaired events of a zipped observable")
+
+// ZipN emits the result of zip with the kth events of all obs, in the same order as obs, once all of them
+// emitted their kth event. To guard against a fast source, at most ZipBuffer unpaired events are buffered
+// per source, once exceeded ErrZipOverflow is emitted and the returned observable completes, because dropping
+// events would mis-pair the following ones.
+// The returned observable completes once any of obs completes and all its events are paired.
+func ZipN(ctx context.Context, zip func([]Event) Event, obs ...*Observable) *Observable {
+	return lazy(func(out *Observable) {
+		ctx, cancel := context.WithCancel(ctx)
+		c := fanIn(ctx, obs)
+		guard := supervise(ctx)
+
+		go func() {
+			defer trackGoroutine()()
+			defer guard(out)
+			defer cancel()
+
+			queues := make([][]Event, len(obs))
+			done := make([]bool, len(obs))
+
+			// completed returns true if a completed source has no more event to pair
+			completed := func() bool {
+				for i, q := range queues {
+					if done[i] && len(q) == 0 {
+						return true
+					}
+				}
+				return false
+			}
+
+			for {
+				select {
+				case <-ctx.Done():
+					out.Close()
+					return
+
+				case ie, ok := <-c:
+					if !ok {
+						out.CloseDrain()
+						return
+					}
+
+					if ie.done {
+						done[ie.i] = true
+					} else {
+						if len(queues[ie.i]) == ZipBuffer {
+							out.Publish(ErrZipOverflow)
+							out.CloseDrain()
+							return
+						}
+						queues[ie.i] = append(queues[ie.i], ie.e)
+					}
+
+					for zipReady(queues) {
+						list := make([]Event, len(queues))
+						for i, q := range queues {
+							list[i] = q[0]
+							q[0] = nil
+							queues[i] = q[1:]
+						}
+						out.Publish(zip(list))
+					}
+
+					if completed() {
+						out.CloseDrain()
+						return
+					}
+				}
+			}
+		}()
+	})
+}
+
+// zipReady returns true if all the queues have at least one event
+func zipReady(queues [][]Event) bool {
+	for _, q := range queues {
+		if len(q) == 0 {
+			return false
+		}
+	}
+	return len(queues) > 0
+}
+
+// SampleWith emits the latest event of source each time notifier emits, if source emitted since the last sample.
+// The returned observable completes once source or notifier completes, or is closed once ctx is done.
+func SampleWith(ctx context.Context, source, notifier *Observable) *Observable {
+	return source.derive(func(out *Observable) {
+		s, n := source.follow(), notifier.follow()
+
+		go func() {
+			defer trackGoroutine()()
+			defer source.Unsubscribe(s)
+			defer notifier.Unsubscribe(n)
+
+			var latest Event
+			has := false
+
+			for {
+				select {
+				case <-ctx.Done():
+					out.Close()
+					return
+
+				case e, ok := <-s:
+					if !ok {
+						out.CloseDrain()
+						return
+					}
+					latest, has = e, true
+
+				case _, ok := <-n:
+					if !ok {
+						out.CloseDrain()
+						return
+					}
+					if has {
+						out.Publish(latest)
+						latest, has = nil, false
+					}
+				}
+			}
+		}()
+	})
+}
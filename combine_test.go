@@ -0,0 +1,206 @@
+package goob_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ysmood/goob"
+)
+
+func TestForkJoin(t *testing.T) {
+	checkLeak(t)
+
+	ctx := context.Background()
+
+	list, err := goob.ForkJoin(ctx,
+		goob.FromSlice([]goob.Event{1}),
+		goob.FromSlice([]goob.Event{1, 2, 3}),
+		goob.FromSlice([]goob.Event{1, 2}),
+	)
+
+	eq(t, nil, err)
+	eq(t, []goob.Event{1, 3, 2}, list)
+}
+
+func TestForkJoinEmpty(t *testing.T) {
+	checkLeak(t)
+
+	_, err := goob.ForkJoin(context.Background(),
+		goob.FromSlice([]goob.Event{1}),
+		goob.FromSlice(nil),
+	)
+
+	eq(t, goob.ErrEmpty, err)
+}
+
+func TestForkJoinCancel(t *testing.T) {
+	checkLeak(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	ob := goob.New()
+	defer ob.Close()
+
+	_, err := goob.ForkJoin(ctx, ob)
+
+	eq(t, context.Canceled, err)
+	eq(t, 0, ob.Len())
+}
+
+func TestMergeLabeled(t *testing.T) {
+	checkLeak(t)
+
+	s := goob.MergeLabeled(context.Background(), map[string]*goob.Observable{
+		"a": goob.FromSlice([]goob.Event{1, 2}),
+		"b": goob.FromSlice([]goob.Event{3}),
+	}).Subscribe()
+
+	list := collect(s)
+
+	eq(t, 3, len(list))
+	got := map[string][]goob.Event{}
+	for _, e := range list {
+		l := e.(goob.Labeled)
+		got[l.Source] = append(got[l.Source], l.Value)
+	}
+	eq(t, map[string][]goob.Event{"a": {1, 2}, "b": {3}}, got)
+}
+
+func TestMergeLabeledCancel(t *testing.T) {
+	checkLeak(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	a := goob.New()
+	b := goob.New()
+	s := goob.MergeLabeled(ctx, map[string]*goob.Observable{"a": a, "b": b}).Subscribe()
+
+	b.Publish(1)
+	eq(t, goob.Labeled{Source: "b", Value: 1}, <-s)
+
+	cancel()
+	collect(s)
+	eq(t, 0, a.Len())
+	eq(t, 0, b.Len())
+}
+
+func TestCombineLatestN(t *testing.T) {
+	checkLeak(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	a, b, c := goob.New(), goob.New(), goob.New()
+	out := goob.CombineLatestN(ctx, func(list []goob.Event) goob.Event {
+		return list
+	}, a, b, c)
+	s := out.Subscribe()
+
+	step := func(ob *goob.Observable, e goob.Event) {
+		ob.Publish(e)
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	step(a, 1)
+	step(b, 1)
+	step(a, 2)
+	eq(t, []goob.Event{}, out.Pending(s))
+
+	step(c, 1)
+	eq(t, []goob.Event{2, 1, 1}, <-s)
+
+	step(b, 2)
+	eq(t, []goob.Event{2, 2, 1}, <-s)
+
+	a.CloseDrain()
+	b.CloseDrain()
+	step(c, 2)
+	eq(t, []goob.Event{2, 2, 2}, <-s)
+
+	c.CloseDrain()
+	eq(t, []goob.Event{}, collect(s))
+}
+
+func TestCombineLatestNEmpty(t *testing.T) {
+	checkLeak(t)
+
+	a := goob.New()
+	defer a.Close()
+
+	s := goob.CombineLatestN(context.Background(), func(list []goob.Event) goob.Event {
+		return list
+	}, a, goob.FromSlice(nil)).Subscribe()
+
+	eq(t, []goob.Event{}, collect(s))
+}
+
+func TestZipN(t *testing.T) {
+	checkLeak(t)
+
+	s := goob.ZipN(context.Background(), func(list []goob.Event) goob.Event {
+		return list
+	},
+		goob.FromSlice([]goob.Event{1, 2, 3}),
+		goob.FromSlice([]goob.Event{"a", "b", "c"}),
+		goob.FromSlice([]goob.Event{true, false, true}),
+	).Subscribe()
+
+	eq(t, []goob.Event{
+		[]goob.Event{1, "a", true},
+		[]goob.Event{2, "b", false},
+		[]goob.Event{3, "c", true},
+	}, collect(s))
+}
+
+func TestZipNOverflow(t *testing.T) {
+	checkLeak(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	a, b := goob.New(), goob.New()
+	defer b.Close()
+
+	s := goob.ZipN(ctx, func(list []goob.Event) goob.Event {
+		return list
+	}, a, b).Subscribe()
+
+	b.Publish("a")
+	time.Sleep(10 * time.Millisecond)
+
+	// the first one is paired, the rest fill the buffer
+	for i := 0; i <= goob.ZipBuffer; i++ {
+		a.Publish(i)
+	}
+	eq(t, []goob.Event{0, "a"}, <-s)
+
+	a.Publish("overflow")
+	eq(t, []goob.Event{goob.ErrZipOverflow}, collect(s))
+	a.Close()
+}
+
+func TestSampleWith(t *testing.T) {
+	checkLeak(t)
+
+	source, notifier := goob.New(), goob.New()
+	defer notifier.Close()
+	s := goob.SampleWith(context.Background(), source, notifier).Subscribe()
+
+	source.Publish(1)
+	settle()
+	notifier.Publish("tick")
+	eq(t, 1, <-s)
+
+	notifier.Publish("tick")
+	settle()
+	source.Publish(2)
+	source.Publish(3)
+	settle()
+	notifier.Publish("tick")
+	eq(t, 3, <-s)
+
+	source.CloseDrain()
+	eq(t, []goob.Event{}, collect(s))
+}
@@ -0,0 +1,280 @@
+package goob
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+// CollectBy drains s and groups the events by key. If ctx is done first it returns what's collected so far.
+func CollectBy[K comparable](ctx context.Context, s <-chan Event, key func(Event) K) map[K][]Event {
+	groups := map[K][]Event{}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return groups
+		case e, ok := <-s:
+			if !ok {
+				return groups
+			}
+			k := key(e)
+			groups[k] = append(groups[k], e)
+		}
+	}
+}
+
+// LastN drains s and returns its last n events in order, fewer if s has less.
+// If ctx is done first it returns the last n events received so far.
+func LastN(ctx context.Context, s <-chan Event, n int) []Event {
+	if n <= 0 {
+		return []Event{}
+	}
+
+	ring := make([]Event, n)
+	count := 0
+
+	list := func() []Event {
+		if count <= n {
+			return ring[:count]
+		}
+		i := count % n
+		return append(ring[i:], ring[:i]...)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return list()
+		case e, ok := <-s:
+			if !ok {
+				return list()
+			}
+			ring[count%n] = e
+			count++
+		}
+	}
+}
+
+// EachBatch calls fn with the events of s in batches, a batch is passed once it has n events or d passed since
+// its first event. The last partial batch is passed when s is closed. fn returns true to stop.
+// An n below 1 is treated as 1.
+func EachBatch(s <-chan Event, n int, d time.Duration, fn func([]Event) bool) {
+	if n < 1 {
+		n = 1
+	}
+
+	batch := make([]Event, 0, n)
+	var timeout <-chan time.Time
+
+	flush := func() bool {
+		timeout = nil
+		if len(batch) == 0 {
+			return false
+		}
+		stop := fn(batch)
+		batch = make([]Event, 0, n)
+		return stop
+	}
+
+	for {
+		select {
+		case e, ok := <-s:
+			if !ok {
+				flush()
+				return
+			}
+			if len(batch) == 0 {
+				timeout = time.After(d)
+			}
+			batch = append(batch, e)
+			if len(batch) >= n && flush() {
+				return
+			}
+
+		case <-timeout:
+			if flush() {
+				return
+			}
+		}
+	}
+}
+
+// Select merges the events of chans into the returned channel, it's closed once all chans are closed or ctx is done.
+// Unlike MergeLabeled it works on raw channels, such as the ones of Subscribe.
+func Select(ctx context.Context, chans ...<-chan Event) <-chan Event {
+	out := make(chan Event)
+	wg := sync.WaitGroup{}
+	wg.Add(len(chans))
+
+	for _, c := range chans {
+		c := c
+
+		go func() {
+			defer trackGoroutine()()
+			defer wg.Done()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case e, ok := <-c:
+					if !ok {
+						return
+					}
+					select {
+					case <-ctx.Done():
+						return
+					case out <- e:
+					}
+				}
+			}
+		}()
+	}
+
+	go func() {
+		defer trackGoroutine()()
+		wg.Wait()
+		close(out)
+	}()
+
+	return out
+}
+
+// StopReason of EachE
+type StopReason int
+
+const (
+	// StopCallback means fn returned true
+	StopCallback StopReason = iota
+
+	// StopComplete means the channel is closed
+	StopComplete
+
+	// StopError means the channel is closed right after an error event, such as the error of MapErr
+	// in the Terminate mode
+	StopError
+)
+
+// EachE calls fn with each event of s, including the error events, until s is closed or fn returns true to stop,
+// and returns why it stopped.
+func EachE(s <-chan Event, fn func(Event) bool) StopReason {
+	var last Event
+	for e := range s {
+		if fn(e) {
+			return StopCallback
+		}
+		last = e
+	}
+
+	if _, ok := last.(error); ok {
+		return StopError
+	}
+	return StopComplete
+}
+
+// EachTimeout calls fn with each event of s until s is closed, fn returns true to stop, or d passes.
+// If idle is true d is measured since the last event, otherwise since EachTimeout is called.
+func EachTimeout(s <-chan Event, d time.Duration, idle bool, fn func(Event) bool) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			return
+		case e, ok := <-s:
+			if !ok || fn(e) {
+				return
+			}
+			if idle {
+				if !timer.Stop() {
+					<-timer.C
+				}
+				timer.Reset(d)
+			}
+		}
+	}
+}
+
+// NewReader returns a reader of the bytes that frame returns for each event of s, in order. Read blocks for the next
+// event once the bytes of the previous ones are read, a frame can span several calls of Read.
+// Read returns io.EOF once s is closed, ctx.Err() once ctx is done, or the error of frame.
+func NewReader(ctx context.Context, s <-chan Event, frame func(Event) ([]byte, error)) io.Reader {
+	return &reader{ctx: ctx, s: s, frame: frame}
+}
+
+type reader struct {
+	ctx   context.Context
+	s     <-chan Event
+	frame func(Event) ([]byte, error)
+
+	// buf is the unread part of the current frame
+	buf []byte
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	for len(r.buf) == 0 {
+		select {
+		case <-r.ctx.Done():
+			return 0, r.ctx.Err()
+		case e, ok := <-r.s:
+			if !ok {
+				return 0, io.EOF
+			}
+			b, err := r.frame(e)
+			if err != nil {
+				return 0, err
+			}
+			r.buf = b
+		}
+	}
+
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// Chan forwards each event of s that is a T to the returned channel, until s is closed or ctx is done, then the
+// returned channel is closed. The other events are passed to mismatch, or skipped if it's nil,
+// mismatch can panic to make a mismatch fatal.
+func Chan[T any](ctx context.Context, s <-chan Event, mismatch func(Event)) <-chan T {
+	out := make(chan T)
+
+	go func() {
+		defer trackGoroutine()()
+		defer close(out)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case e, ok := <-s:
+				if !ok {
+					return
+				}
+
+				v, ok := e.(T)
+				if !ok {
+					if mismatch != nil {
+						mismatch(e)
+					}
+					continue
+				}
+
+				select {
+				case <-ctx.Done():
+					return
+				case out <- v:
+				}
+			}
+		}
+	}()
+
+	return out
+}
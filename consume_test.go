@@ -0,0 +1,265 @@
+package goob_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/ysmood/goob"
+)
+
+func TestCollectBy(t *testing.T) {
+	checkLeak(t)
+
+	s := goob.FromSlice([]goob.Event{1, 2, 3, 4, 5}).Subscribe()
+
+	groups := goob.CollectBy(context.Background(), s, func(e goob.Event) bool {
+		return e.(int)%2 == 0
+	})
+
+	eq(t, map[bool][]goob.Event{
+		true:  {2, 4},
+		false: {1, 3, 5},
+	}, groups)
+}
+
+func TestCollectByCancel(t *testing.T) {
+	checkLeak(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ob := goob.New()
+	defer ob.Close()
+	s := ob.Subscribe()
+
+	ob.Publish(1)
+	ob.Publish(2)
+
+	go func() {
+		for ob.Subscribers()[0].Pending > 0 {
+			time.Sleep(time.Millisecond)
+		}
+		cancel()
+	}()
+
+	groups := goob.CollectBy(ctx, s, func(e goob.Event) int { return e.(int) })
+
+	eq(t, map[int][]goob.Event{1: {1}, 2: {2}}, groups)
+}
+
+func TestLastN(t *testing.T) {
+	checkLeak(t)
+
+	ctx := context.Background()
+	ob := goob.FromSlice([]goob.Event{0, 1, 2, 3, 4, 5, 6, 7, 8, 9})
+
+	eq(t, []goob.Event{7, 8, 9}, goob.LastN(ctx, ob.Subscribe(), 3))
+	eq(t, []goob.Event{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, goob.LastN(ctx, ob.Subscribe(), 20))
+	eq(t, []goob.Event{}, goob.LastN(ctx, ob.Subscribe(), 0))
+}
+
+func TestEachBatch(t *testing.T) {
+	checkLeak(t)
+
+	ob := goob.New()
+	s := ob.Subscribe()
+
+	go func() {
+		for i := 0; i < 5; i++ {
+			ob.Publish(i)
+		}
+		time.Sleep(10 * time.Millisecond)
+		ob.Publish(5)
+		time.Sleep(50 * time.Millisecond)
+		ob.Publish(6)
+		ob.CloseDrain()
+	}()
+
+	batches := []goob.Event{}
+	goob.EachBatch(s, 2, 30*time.Millisecond, func(list []goob.Event) bool {
+		batches = append(batches, list)
+		return false
+	})
+
+	eq(t, []goob.Event{
+		[]goob.Event{0, 1},
+		[]goob.Event{2, 3},
+		[]goob.Event{4, 5}, // size
+		[]goob.Event{6},    // close
+	}, batches)
+}
+
+func TestEachBatchTimeout(t *testing.T) {
+	checkLeak(t)
+
+	ob := goob.New()
+	defer ob.Close()
+	s := ob.Subscribe()
+
+	ob.Publish(1)
+
+	batches := []goob.Event{}
+	goob.EachBatch(s, 10, 10*time.Millisecond, func(list []goob.Event) bool {
+		batches = append(batches, list)
+		return true
+	})
+
+	eq(t, []goob.Event{[]goob.Event{1}}, batches)
+}
+
+func TestSelect(t *testing.T) {
+	checkLeak(t)
+
+	a := goob.FromSlice([]goob.Event{1, 2})
+	b := goob.FromSlice([]goob.Event{3})
+	c := make(chan goob.Event, 1)
+	c <- 4
+	close(c)
+
+	s := goob.Select(context.Background(), a.Subscribe(), b.Subscribe(), c)
+
+	got := map[goob.Event]bool{}
+	for e := range s {
+		got[e] = true
+	}
+	eq(t, map[goob.Event]bool{1: true, 2: true, 3: true, 4: true}, got)
+}
+
+func TestSelectCancel(t *testing.T) {
+	checkLeak(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := goob.Select(ctx, make(chan goob.Event))
+	cancel()
+
+	_, ok := <-s
+	eq(t, false, ok)
+}
+
+func TestEachTimeout(t *testing.T) {
+	checkLeak(t)
+
+	ob := goob.New()
+	defer ob.Close()
+
+	stop := make(chan struct{})
+	defer close(stop)
+
+	// the stream keeps producing
+	go func() {
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			case <-time.After(5 * time.Millisecond):
+				ob.Publish(i)
+			}
+		}
+	}()
+
+	start := time.Now()
+	count := 0
+	goob.EachTimeout(ob.Subscribe(), 30*time.Millisecond, false, func(goob.Event) bool {
+		count++
+		return false
+	})
+	eq(t, true, time.Since(start) >= 30*time.Millisecond)
+	eq(t, true, count > 0)
+
+	// idle timeout doesn't fire while events keep arriving, fn stops it
+	count = 0
+	goob.EachTimeout(ob.Subscribe(), 30*time.Millisecond, true, func(goob.Event) bool {
+		count++
+		return count == 10
+	})
+	eq(t, 10, count)
+
+	// idle timeout fires after the stream goes quiet
+	start = time.Now()
+	goob.EachTimeout(goob.New().Subscribe(), 10*time.Millisecond, true, func(goob.Event) bool { return false })
+	eq(t, true, time.Since(start) >= 10*time.Millisecond)
+}
+
+func TestNewReader(t *testing.T) {
+	checkLeak(t)
+
+	line := func(e goob.Event) ([]byte, error) { return []byte(e.(string) + "\n"), nil }
+
+	s := goob.FromSlice([]goob.Event{"hello", "", "world"}).Subscribe()
+	r := goob.NewReader(context.Background(), s, line)
+
+	p := make([]byte, 4)
+	list := []string{}
+	for {
+		n, err := r.Read(p)
+		if err == io.EOF {
+			break
+		}
+		eq(t, nil, err)
+		list = append(list, string(p[:n]))
+	}
+	eq(t, []string{"hell", "o\n", "\n", "worl", "d\n"}, list)
+
+	errFrame := errors.New("frame")
+	ob := goob.New()
+	defer ob.Close()
+	s = ob.Subscribe()
+	ob.Publish(1)
+	_, err := goob.NewReader(context.Background(), s, func(goob.Event) ([]byte, error) { return nil, errFrame }).Read(p)
+	eq(t, errFrame, err)
+}
+
+func TestChan(t *testing.T) {
+	checkLeak(t)
+
+	s := goob.FromSlice([]goob.Event{1, "a", 2}).Subscribe()
+
+	mismatched := []goob.Event{}
+	list := []int{}
+	for v := range goob.Chan[int](context.Background(), s, func(e goob.Event) {
+		mismatched = append(mismatched, e)
+	}) {
+		list = append(list, v)
+	}
+
+	eq(t, []int{1, 2}, list)
+	eq(t, []goob.Event{"a"}, mismatched)
+
+	s = goob.FromSlice([]goob.Event{"b", 3}).Subscribe()
+	eq(t, 3, <-goob.Chan[int](context.Background(), s, nil))
+}
+
+func TestEachE(t *testing.T) {
+	checkLeak(t)
+
+	list := []goob.Event{}
+	reason := goob.EachE(goob.FromSlice([]goob.Event{1, 2}).Subscribe(), func(e goob.Event) bool {
+		list = append(list, e)
+		return false
+	})
+	eq(t, goob.StopComplete, reason)
+	eq(t, []goob.Event{1, 2}, list)
+
+	reason = goob.EachE(goob.FromSlice([]goob.Event{1, 2, 3}).Subscribe(), func(e goob.Event) bool { return e == 2 })
+	eq(t, goob.StopCallback, reason)
+
+	errFailed := errors.New("failed")
+	reason = goob.EachE(goob.FromSlice([]goob.Event{errFailed, 1}).Subscribe(), func(goob.Event) bool { return false })
+	eq(t, goob.StopComplete, reason)
+	reason = goob.EachE(goob.FromSlice([]goob.Event{1, errFailed}).Subscribe(), func(goob.Event) bool { return false })
+	eq(t, goob.StopError, reason)
+}
+
+func TestEachBatchNonPositive(t *testing.T) {
+	checkLeak(t)
+
+	batches := []goob.Event{}
+	goob.EachBatch(goob.FromSlice([]goob.Event{1, 2}).Subscribe(), -1, time.Second, func(list []goob.Event) bool {
+		batches = append(batches, list)
+		return false
+	})
+
+	eq(t, []goob.Event{[]goob.Event{1}, []goob.Event{2}}, batches)
+}
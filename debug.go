@@ -0,0 +1,45 @@
+package goob
+
+import (
+	"log"
+	"runtime"
+	"runtime/debug"
+	"sync/atomic"
+)
+
+// Debug enables the leak check of operators, it should only be used during development.
+// When an observable returned by an operator is garbage collected while the operator's goroutine is
+// still running, a warning with the stack that created the operator is logged via the standard log.
+// It usually means the ctx of the operator will never be done.
+var Debug = false
+
+func track(ob *Observable) {
+	stack := debug.Stack()
+
+	runtime.SetFinalizer(ob, func(ob *Observable) {
+		ob.lock.Lock()
+		running := ob.start == nil && ob.subscribers != nil
+		ob.lock.Unlock()
+
+		if running {
+			log.Printf("goob: the operator is garbage collected while its goroutine is still running, created at:\n%s", stack)
+		}
+	})
+}
+
+// activeGoroutines is the counter of ActiveGoroutines
+var activeGoroutines int64
+
+// ActiveGoroutines returns the number of running goroutines that goob started, such as the ones of operators
+// and subscribers, it's cheaper and more precise than runtime.NumGoroutine for a leak check. It should return to its previous value once a pipeline
+// is closed or its ctx is done, otherwise some goroutines leaked.
+func ActiveGoroutines() int {
+	return int(atomic.LoadInt64(&activeGoroutines))
+}
+
+// trackGoroutine counts the calling goroutine in ActiveGoroutines, the returned func should be deferred to uncount it.
+// It doesn't depend on Debug, because the goroutines would read Debug while the user sets it.
+func trackGoroutine() func() {
+	atomic.AddInt64(&activeGoroutines, 1)
+	return func() { atomic.AddInt64(&activeGoroutines, -1) }
+}
@@ -0,0 +1,141 @@
+package goob_test
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ysmood/goob"
+)
+
+type syncBuffer struct {
+	lock sync.Mutex
+	buf  bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buf.String()
+}
+
+func TestDebugLeak(t *testing.T) {
+	checkLeak(t)
+
+	out := &syncBuffer{}
+	log.SetOutput(out)
+	defer log.SetOutput(os.Stderr)
+
+	goob.Debug = true
+	defer func() { goob.Debug = false }()
+
+	ob := goob.New()
+	defer ob.Close()
+
+	func() {
+		ob.Map(context.Background(), func(e goob.Event) goob.Event { return e }).Subscribe()
+	}()
+
+	for i := 0; !strings.Contains(out.String(), "TestDebugLeak"); i++ {
+		if i == 100 {
+			t.Fatal("no warning", out.String())
+		}
+		runtime.GC()
+		time.Sleep(time.Millisecond)
+	}
+
+	eq(t, true, strings.Contains(out.String(), "goob: the operator is garbage collected"))
+}
+
+func TestDebugNoLeak(t *testing.T) {
+	checkLeak(t)
+
+	out := &syncBuffer{}
+	log.SetOutput(out)
+	defer log.SetOutput(os.Stderr)
+
+	goob.Debug = true
+	defer func() { goob.Debug = false }()
+
+	ob := goob.New()
+	defer ob.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	func() {
+		s := ob.Map(ctx, func(e goob.Event) goob.Event { return e }).Subscribe()
+		cancel()
+		for range s {
+		}
+	}()
+
+	for i := 0; i < 10; i++ {
+		runtime.GC()
+		time.Sleep(time.Millisecond)
+	}
+
+	eq(t, "", out.String())
+}
+
+func TestActiveGoroutines(t *testing.T) {
+	checkLeak(t)
+
+	base := goob.ActiveGoroutines()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ob := goob.New()
+	defer ob.Close()
+
+	s := ob.Map(ctx, func(e goob.Event) goob.Event { return e }).
+		Filter(ctx, func(e goob.Event) bool { return true }).Subscribe()
+	ob.Publish(1)
+	eq(t, 1, <-s)
+	eq(t, true, goob.ActiveGoroutines() > base)
+
+	cancel()
+	for range s {
+	}
+
+	waitGoroutines(t, base)
+}
+
+// waitGoroutines waits for ActiveGoroutines to return to base
+func waitGoroutines(t *testing.T, base int) {
+	t.Helper()
+
+	for i := 0; goob.ActiveGoroutines() > base; i++ {
+		if i == 100 {
+			t.Fatal("leaked", goob.ActiveGoroutines()-base)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestUnsubscribeDraining(t *testing.T) {
+	checkLeak(t)
+
+	base := goob.ActiveGoroutines()
+
+	src := goob.FromSlice([]goob.Event{1, 2, 3, 4, 5})
+	s := src.Take(context.Background(), 2).Subscribe()
+	eq(t, []goob.Event{1, 2}, collect(s))
+	waitGoroutines(t, base)
+	eq(t, 0, src.CountDraining())
+
+	s = src.Subscribe()
+	eq(t, 1, <-s)
+	src.Unsubscribe(s)
+	waitGoroutines(t, base)
+	eq(t, 0, src.CountDraining())
+}
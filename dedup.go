@@ -0,0 +1,101 @@
+package goob
+
+import (
+	"container/list"
+	"context"
+	"time"
+)
+
+// PublishOnce publishes e only if key isn't one of the recent keys of PublishOnce, it returns true if published,
+// false if a middleware dropped it, see WithPublishMiddleware.
+// The number of recent keys is set by the DedupWindow option.
+func (ob *Observable) PublishOnce(key string, e Event) bool {
+	e = ob.snapshot(e)
+	if ob.middleware != nil {
+		published := false
+		ob.intercept(e, func(e Event) { published = ob.publishOnce(key, e) })
+		return published
+	}
+	return ob.publishOnce(key, e)
+}
+
+func (ob *Observable) publishOnce(key string, e Event) bool {
+	ob.lock.Lock()
+	defer ob.lock.Unlock()
+
+	if ob.dedup == nil {
+		ob.dedup = newLRU(ob.dedupWindow)
+	}
+
+	if !ob.dedup.add(key) {
+		return false
+	}
+
+	ob.publish(e)
+	return true
+}
+
+// SubscribeDedupWindow is like Subscribe, but an event is dropped if eq reports it equal to an event that was passed
+// to the subscriber within the last d, measured by the clock of ob. It unsubscribes when ctx is done.
+// If eq is nil the default of Equality is used.
+func (ob *Observable) SubscribeDedupWindow(ctx context.Context, d time.Duration, eq func(a, b Event) bool) Subscriber {
+	eq = ob.equality(eq)
+
+	type entry struct {
+		e  Event
+		at time.Time
+	}
+
+	// the filter runs under the lock
+	recent := []entry{}
+
+	return ob.subscribe(ctx, &subscriber{filter: func(e Event) bool {
+		now := ob.clock.Now()
+
+		i := 0
+		for i < len(recent) && now.Sub(recent[i].at) >= d {
+			i++
+		}
+		recent = recent[i:]
+
+		for _, r := range recent {
+			if eq(r.e, e) {
+				return false
+			}
+		}
+
+		recent = append(recent, entry{e, now})
+		return true
+	}})
+}
+
+// lru set of keys
+type lru struct {
+	size  int
+	order *list.List
+	index map[string]*list.Element
+}
+
+func newLRU(size int) *lru {
+	return &lru{
+		size:  size,
+		order: list.New(),
+		index: map[string]*list.Element{},
+	}
+}
+
+// add key as the most recent one, returns false if it's already in the set
+func (l *lru) add(key string) bool {
+	if el, has := l.index[key]; has {
+		l.order.MoveToFront(el)
+		return false
+	}
+
+	l.index[key] = l.order.PushFront(key)
+
+	if l.order.Len() > l.size {
+		delete(l.index, l.order.Remove(l.order.Back()).(string))
+	}
+
+	return true
+}
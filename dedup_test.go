@@ -0,0 +1,56 @@
+package goob_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ysmood/goob"
+)
+
+func TestPublishOnce(t *testing.T) {
+	checkLeak(t)
+
+	ob := goob.New(goob.DedupWindow(2))
+	defer ob.Close()
+	s := ob.Subscribe()
+
+	eq(t, true, ob.PublishOnce("a", 1))
+	eq(t, false, ob.PublishOnce("a", 2))
+	eq(t, true, ob.PublishOnce("b", 3))
+	eq(t, true, ob.PublishOnce("c", 4))
+
+	// "a" is out of the window
+	eq(t, true, ob.PublishOnce("a", 5))
+	eq(t, false, ob.PublishOnce("c", 6))
+
+	eq(t, 1, <-s)
+	eq(t, 3, <-s)
+	eq(t, 4, <-s)
+	eq(t, 5, <-s)
+	settle()
+	eq(t, []goob.Event{}, ob.Pending(s))
+}
+
+func TestSubscribeDedupWindow(t *testing.T) {
+	checkLeak(t)
+
+	clock := goob.NewTestClock()
+	ob := goob.New(goob.WithClock(clock))
+
+	same := func(a, b goob.Event) bool { return a == b }
+	s := ob.SubscribeDedupWindow(context.Background(), time.Second, same)
+	other := ob.Subscribe()
+
+	ob.Publish("alert")
+	ob.Publish("alert")
+	ob.Publish("other")
+	clock.Advance(500 * time.Millisecond)
+	ob.Publish("alert")
+	clock.Advance(500 * time.Millisecond)
+	ob.Publish("alert")
+	ob.CloseDrain()
+
+	eq(t, []goob.Event{"alert", "other", "alert"}, collect(s))
+	eq(t, 5, len(collect(other)))
+}
@@ -0,0 +1,97 @@
+package goob
+
+import (
+	"context"
+	"time"
+)
+
+// Envelope of a published event, see WithEnvelope
+type Envelope struct {
+	// Seq starts from 1 and increases by 1 for each published event of the observable
+	Seq uint64
+
+	// Time when the event is published, via the clock of the observable
+	Time time.Time
+
+	// TypeID of Value in the Registry of WithRegistry, it's empty if the type isn't registered
+	TypeID string
+
+	Value Event
+}
+
+// SubscribeFrom is like Subscribe, but only the envelopes whose Seq is greater than seq are received,
+// so with the Replay option the retained envelopes after seq are replayed before the live ones.
+// It unsubscribes when ctx is done. The events that aren't Envelope are always received.
+func (ob *Observable) SubscribeFrom(ctx context.Context, seq uint64) Subscriber {
+	return ob.subscribe(ctx, &subscriber{filter: after(seq)})
+}
+
+// after returns a filter that only passes the envelopes whose Seq is greater than seq, and the other events
+func after(seq uint64) func(Event) bool {
+	return func(e Event) bool {
+		env, ok := e.(Envelope)
+		return !ok || env.Seq > seq
+	}
+}
+
+// currentSeq is the Seq of the last published Envelope
+func (ob *Observable) currentSeq() uint64 {
+	ob.lock.Lock()
+	defer ob.lock.Unlock()
+	return ob.seq
+}
+
+// Gap of the missing Seq range of a sequenced stream, both ends are included
+type Gap struct {
+	From uint64
+	To   uint64
+}
+
+// DetectGaps watches the Seq of the envelopes of ob and emits a Gap whenever a Seq isn't the next one of the
+// previous envelope, such as after PublishWithDeadline drops. Only gaps are emitted, other events are ignored.
+func (ob *Observable) DetectGaps(ctx context.Context) *Observable {
+	var last uint64
+
+	return ob.operate(ctx, func(out *Observable, e Event) bool {
+		env, ok := e.(Envelope)
+		if !ok {
+			return true
+		}
+
+		if last != 0 && env.Seq > last+1 {
+			out.Publish(Gap{last + 1, env.Seq - 1})
+		}
+		last = env.Seq
+		return true
+	})
+}
+
+// Range returns the retained envelopes whose Seq is between from and to, both ends are included, in Seq order.
+// It needs WithEnvelope and a replay option such as Replay. It's a read of the retained events, it doesn't
+// subscribe. The returned complete is false if some of the published envelopes in the range are no longer retained,
+// the Seq values after the last published one don't count.
+func (ob *Observable) Range(from, to uint64) (list []Event, complete bool) {
+	ob.lock.Lock()
+	defer ob.lock.Unlock()
+
+	if to > ob.seq {
+		to = ob.seq
+	}
+	if from == 0 {
+		from = 1
+	}
+	if from > to {
+		return []Event{}, true
+	}
+
+	list = []Event{}
+	if ob.history != nil {
+		for _, e := range ob.history.events() {
+			if env, ok := e.(Envelope); ok && env.Seq >= from && env.Seq <= to {
+				list = append(list, e)
+			}
+		}
+	}
+
+	return list, uint64(len(list)) == to-from+1
+}
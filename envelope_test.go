@@ -0,0 +1,160 @@
+package goob_test
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ysmood/goob"
+)
+
+func TestEnvelope(t *testing.T) {
+	checkLeak(t)
+
+	clock := goob.NewTestClock()
+	ob := goob.New(goob.WithEnvelope(), goob.WithClock(clock))
+	defer ob.Close()
+	s := ob.Subscribe()
+
+	ob.Publish("a")
+	clock.Advance(time.Second)
+	ob.Publish("b")
+
+	eq(t, goob.Envelope{Seq: 1, Time: time.Unix(0, 0), Value: "a"}, <-s)
+	eq(t, goob.Envelope{Seq: 2, Time: time.Unix(1, 0), Value: "b"}, <-s)
+}
+
+func TestReplay(t *testing.T) {
+	checkLeak(t)
+
+	ob := goob.New(goob.Replay(2))
+	defer ob.Close()
+
+	ob.Publish(1)
+	ob.Publish(2)
+	ob.Publish(3)
+
+	s := ob.Subscribe()
+	ob.Publish(4)
+
+	eq(t, 2, <-s)
+	eq(t, 3, <-s)
+	eq(t, 4, <-s)
+}
+
+func TestSubscribeFrom(t *testing.T) {
+	checkLeak(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ob := goob.New(goob.WithEnvelope(), goob.Replay(0))
+	defer ob.Close()
+
+	for i := 0; i < 5; i++ {
+		ob.Publish(i)
+	}
+
+	s := ob.SubscribeFrom(ctx, 3)
+	ob.Publish(5)
+
+	eq(t, uint64(4), (<-s).(goob.Envelope).Seq)
+	eq(t, uint64(5), (<-s).(goob.Envelope).Seq)
+	eq(t, goob.Event(5), (<-s).(goob.Envelope).Value)
+}
+
+func TestDetectGaps(t *testing.T) {
+	checkLeak(t)
+
+	ctx := context.Background()
+	ob := goob.New(goob.WithEnvelope())
+
+	// simulate the loss of Seq 3, 5 and 6
+	lossy := ob.Filter(ctx, func(e goob.Event) bool {
+		seq := e.(goob.Envelope).Seq
+		return seq != 3 && seq != 5 && seq != 6
+	})
+	s := lossy.DetectGaps(ctx).Subscribe()
+
+	for i := 0; i < 7; i++ {
+		ob.Publish(i)
+	}
+	ob.CloseDrain()
+
+	eq(t, []goob.Event{goob.Gap{From: 3, To: 3}, goob.Gap{From: 5, To: 6}}, collect(s))
+}
+
+func TestReplayLiveBoundary(t *testing.T) {
+	checkLeak(t)
+
+	const total = 5000
+
+	ob := goob.New(goob.WithEnvelope(), goob.Replay(50))
+
+	go func() {
+		for i := 0; i < total; i++ {
+			ob.Publish(i)
+		}
+		ob.CloseDrain()
+	}()
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			var last uint64
+			for e := range ob.Subscribe() {
+				seq := e.(goob.Envelope).Seq
+				if last != 0 && seq != last+1 {
+					t.Error("not contiguous", last, seq)
+					return
+				}
+				last = seq
+			}
+			if last != total {
+				t.Error("incomplete", last)
+			}
+		}()
+		time.Sleep(time.Duration(rand.Intn(100)) * time.Microsecond)
+	}
+	wg.Wait()
+}
+
+func TestRange(t *testing.T) {
+	checkLeak(t)
+
+	ob := goob.New(goob.WithEnvelope(), goob.Replay(100))
+	defer ob.Close()
+
+	for i := 1; i <= 150; i++ {
+		ob.Publish(i)
+	}
+
+	seqs := func(list []goob.Event) []uint64 {
+		out := []uint64{}
+		for _, e := range list {
+			out = append(out, e.(goob.Envelope).Seq)
+		}
+		return out
+	}
+
+	list, complete := ob.Range(60, 63)
+	eq(t, []uint64{60, 61, 62, 63}, seqs(list))
+	eq(t, true, complete)
+
+	list, complete = ob.Range(49, 52)
+	eq(t, []uint64{51, 52}, seqs(list))
+	eq(t, false, complete)
+
+	list, complete = ob.Range(149, 1000)
+	eq(t, []uint64{149, 150}, seqs(list))
+	eq(t, true, complete)
+
+	list, complete = ob.Range(200, 300)
+	eq(t, []goob.Event{}, list)
+	eq(t, true, complete)
+}
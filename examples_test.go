@@ -1,7 +1,9 @@
 package goob_test
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	"github.com/ysmood/goob"
 )
@@ -29,3 +31,42 @@ func Example_basic() {
 
 	// Output: 123
 }
+
+func Example_pipe() {
+	ctx := context.Background()
+
+	events := goob.FromSlice([]goob.Event{1, 2, 3, 4, 5, 6}).Pipe(ctx,
+		goob.FilterOp(func(e goob.Event) bool { return e.(int)%2 == 0 }),
+		goob.MapOp(func(e goob.Event) goob.Event { return e.(int) * 10 }),
+		goob.TakeOp(2),
+	).Subscribe()
+
+	for e := range events {
+		fmt.Println(e)
+	}
+
+	// Output:
+	// 20
+	// 40
+}
+
+func Example_test_clock() {
+	// the test clock only moves when it's advanced, so time-based operators can be tested deterministically
+	clock := goob.NewTestClock()
+	ob := goob.New(goob.WithClock(clock))
+	defer ob.Close()
+
+	events := ob.Debounce(context.Background(), 10*time.Millisecond).Subscribe()
+
+	ob.Publish(1)
+	clock.BlockUntil(1) // wait for Debounce to receive the event
+	clock.Advance(5 * time.Millisecond)
+
+	ob.Publish(2)
+	clock.BlockUntil(2) // the superseded timer of 1 still counts until it's due
+	clock.Advance(10 * time.Millisecond)
+
+	fmt.Println(<-events)
+
+	// Output: 2
+}
@@ -1,3 +1,3 @@
 module github.com/ysmood/goob
 
-go 1.15
+go 1.21
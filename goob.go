@@ -1,73 +1,723 @@
 package goob
 
 import (
+	"context"
+	"log/slog"
+	"runtime"
+	"sort"
+	"strconv"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // Observable hub
 type Observable struct {
+	*observable
+}
+
+// observable is the state of an Observable, so that internal goroutines can hold it via a different
+// Observable than the one returned to the user, see Debug.
+type observable struct {
 	lock        *sync.Mutex
-	subscribers map[Subscriber]*Pipe
+	subscribers map[Subscriber]*subscriber
+	history     history
+	lastID      uint64
+
+	// drained is set by CloseDrain
+	drained bool
+
+	// draining subscribers are ended but still have events to deliver
+	draining map[Subscriber]*subscriber
+
+	// start is called once before the first subscriber is added
+	start func()
+
+	maxPending int
+	clock      Clock
+	errorMode  ErrorMode
+
+	// logger of WithLogger
+	logger *slog.Logger
+
+	// mapper creates the per-subscriber transform of each new subscriber, see Enumerate
+	mapper func() func(Event) Event
+
+	// equal is the default equality of Equality
+	equal func(a, b Event) bool
+
+	// envelope is set by WithEnvelope, seq is the Seq of the last Envelope
+	envelope bool
+	seq      uint64
+	registry *Registry
+
+	// dedup keys of PublishOnce, it's created on demand with the size of dedupWindow
+	dedup       *lru
+	dedupWindow int
+
+	// queue is set by NewQueued, it's signaled when a subscriber receives an event or is removed
+	queue    *sync.Cond
+	queueMax int
+
+	// async events of PublishAsync that are waiting to be published, asyncRunning is set while they are published
+	async        []Event
+	asyncRunning bool
+
+	// copy hook of CopyOnPublish
+	copier func(Event) Event
+
+	// middleware of WithPublishMiddleware, in order
+	middleware []func(e Event, next func(Event))
+
+	// lifecycle callbacks, see OnSubscribe and OnUnsubscribe
+	onSubscribe   func(id uint64)
+	onUnsubscribe func(id uint64, reason error)
+
+	// the last published event, see TrackLatest
+	trackLatest bool
+	latest      Event
+	hasLatest   bool
+
+	// latency is set by TrackLatency
+	latency bool
+
+	// stuckTimeout of StuckTimeout, watching is set while the watchdog is running
+	stuckTimeout time.Duration
+	watching     bool
+	stopWatching func()
+}
+
+type subscriber struct {
+	*Pipe
+	id      uint64
+	name    string
+	created time.Time
+
+	// filter decides if an event is written to the subscriber, nil means all
+	filter func(Event) bool
+
+	// operator is set by follow, Close drains it rather than stopping it
+	operator bool
+
+	// mapper transforms each event written to the subscriber, see observable.mapper
+	mapper func(Event) Event
+
+	// discarded is the number of events dropped after the pipe delivered them, see SubscribeTiered
+	discarded int64
+
+	// reset the state of filter, see ResetAfter
+	reset func()
+
+	// buffer of the pipe, nil means the default one
+	buffer *ring
+
+	// reason of the eviction, it's set under the lock before the pipe is stopped
+	reason error
+
+	// latency of the received envelopes, see TrackLatency
+	latency *histogram
+
+	// latest is set by SubscribeLatest
+	latest bool
+}
+
+func (p *subscriber) write(e Event) {
+	if p.filter == nil || p.filter(e) {
+		p.Write(p.transform(e))
+	}
+}
+
+// writeUntil is like write, but e is dropped if it isn't received before the deadline, a zero deadline means none
+func (p *subscriber) writeUntil(e Event, deadline time.Time, clock Clock) {
+	if deadline.IsZero() {
+		p.write(e)
+	} else if p.filter == nil || p.filter(e) {
+		p.Write(deadlined{p.transform(e), deadline, clock})
+	}
+}
+
+// transform e with the mapper of p, if any
+func (p *subscriber) transform(e Event) Event {
+	if p.mapper == nil {
+		return e
+	}
+	return p.mapper(e)
+}
+
+// history retains published events to replay them to new subscribers
+type history interface {
+	add(e Event)
+	events() []Event
 }
 
 // Subscriber type
 type Subscriber <-chan Event
 
 // New observable instance
-func New() *Observable {
-	ob := &Observable{
+func New(opts ...Option) *Observable {
+	ob := &Observable{&observable{
 		lock:        &sync.Mutex{},
-		subscribers: map[Subscriber]*Pipe{},
+		subscribers: map[Subscriber]*subscriber{},
+		draining:    map[Subscriber]*subscriber{},
+		clock:       realClock{},
+		dedupWindow: 1024,
+	}}
+
+	for _, opt := range opts {
+		opt(ob.observable)
 	}
+
 	return ob
 }
 
-// Publish message to the queue
+// Publish message to the queue. When it returns the event is already buffered for every current subscriber,
+// so it will be delivered before the events of any later Publish. Concurrent calls are serialized, so all the
+// subscribers receive the events in the same order.
+// It never waits for subscribers, so it's safe to call inside a handler of a subscriber, except for an observable
+// of NewQueued, where it blocks while the handler's own subscriber is full, use PublishAsync there.
 func (ob *Observable) Publish(e Event) {
+	e = ob.snapshot(e)
+	if ob.middleware != nil {
+		ob.intercept(e, ob.publishNow)
+		return
+	}
+	ob.publishNow(e)
+}
+
+func (ob *Observable) publishNow(e Event) {
 	ob.lock.Lock()
 	defer ob.lock.Unlock()
 
+	ob.publish(e)
+}
+
+// PublishFrom subscribes src and publishes each of its events to ob, until ctx is done or src completes
+func (ob *Observable) PublishFrom(ctx context.Context, src *Observable) {
+	s := src.subscribe(ctx, &subscriber{})
+
+	go func() {
+		defer trackGoroutine()()
+		for e := range s {
+			ob.Publish(e)
+		}
+	}()
+}
+
+// snapshot returns the copy of e made by the hook of CopyOnPublish, or e if there's none
+func (ob *Observable) snapshot(e Event) Event {
+	if ob.copier == nil {
+		return e
+	}
+	return ob.copier(e)
+}
+
+// intercept passes e through the middleware in order, the last one's next calls final
+func (ob *Observable) intercept(e Event, final func(Event)) {
+	var next func(i int, e Event)
+	next = func(i int, e Event) {
+		if i == len(ob.middleware) {
+			final(e)
+			return
+		}
+		ob.middleware[i](e, func(e Event) { next(i+1, e) })
+	}
+	next(0, e)
+}
+
+// publish e, it must be called under the lock
+func (ob *Observable) publish(e Event) {
+	ob.publishUntil(e, time.Time{})
+}
+
+// publishUntil is like publish, but e is dropped for the subscribers that don't receive it before the deadline
+func (ob *Observable) publishUntil(e Event, deadline time.Time) {
+	ob.waitQueue()
+
+	if ob.subscribers == nil {
+		return
+	}
+
+	if ob.envelope {
+		ob.seq++
+		env := Envelope{Seq: ob.seq, Time: ob.clock.Now(), Value: e}
+		if ob.registry != nil {
+			env.TypeID, _ = ob.registry.TypeID(e)
+		}
+		e = env
+	}
+
+	if ob.history != nil {
+		ob.history.add(e)
+	}
+
+	if ob.trackLatest {
+		ob.latest, ob.hasLatest = e, true
+	}
+
 	for _, p := range ob.subscribers {
-		p.Write(e)
+		p.writeUntil(e, deadline, ob.clock)
 	}
 }
 
+// PublishAsync enqueues e and returns without waiting, the queued events are published in order by a background
+// goroutine, so it's safe for a handler to feed events back into ob even when Publish would block.
+func (ob *Observable) PublishAsync(e Event) {
+	e = ob.snapshot(e)
+	if ob.middleware != nil {
+		ob.intercept(e, ob.enqueue)
+		return
+	}
+	ob.enqueue(e)
+}
+
+func (ob *Observable) enqueue(e Event) {
+	ob.lock.Lock()
+	defer ob.lock.Unlock()
+
+	ob.async = append(ob.async, e)
+	if ob.asyncRunning {
+		return
+	}
+	ob.asyncRunning = true
+
+	go func() {
+		defer trackGoroutine()()
+		ob.lock.Lock()
+		defer ob.lock.Unlock()
+
+		for len(ob.async) > 0 {
+			e := ob.async[0]
+			ob.async[0] = nil
+			ob.async = ob.async[1:]
+			ob.publish(e)
+		}
+		ob.async = nil
+		ob.asyncRunning = false
+	}()
+}
+
+// PublishWithDeadline is like Publish, but a subscriber that doesn't receive e within d drops it,
+// see SubscriberInfo.Dropped. Replays of the retained e, if any, have no deadline.
+func (ob *Observable) PublishWithDeadline(e Event, d time.Duration) {
+	e = ob.snapshot(e)
+	publish := func(e Event) {
+		ob.lock.Lock()
+		defer ob.lock.Unlock()
+
+		ob.publishUntil(e, ob.clock.Now().Add(d))
+	}
+
+	if ob.middleware != nil {
+		ob.intercept(e, publish)
+		return
+	}
+	publish(e)
+}
+
 // Subscribe message
 func (ob *Observable) Subscribe() Subscriber {
+	return ob.subscribe(context.Background(), &subscriber{})
+}
+
+// follow is the Subscribe of operators, Close drains the subscriber so that the operator receives all the events
+// published before Close, then sees the completion
+func (ob *Observable) follow() Subscriber {
+	return ob.subscribe(context.Background(), &subscriber{operator: true})
+}
+
+// SubscribeNamed is like Subscribe, but the subscriber is reported under the name by Subscribers,
+// and it unsubscribes when ctx is done.
+func (ob *Observable) SubscribeNamed(ctx context.Context, name string) Subscriber {
+	return ob.subscribe(ctx, &subscriber{name: name})
+}
+
+// SubscribeAfter is like Subscribe, but the events before the first event that trigger returns true for are dropped.
+// Call ResetAfter to drop the events again until trigger returns true for another event.
+// It unsubscribes when ctx is done.
+func (ob *Observable) SubscribeAfter(ctx context.Context, trigger func(Event) bool) Subscriber {
+	// the filter and reset both run under the lock
+	triggered := false
+	return ob.subscribe(ctx, &subscriber{
+		filter: func(e Event) bool {
+			if !triggered {
+				triggered = trigger(e)
+			}
+			return triggered
+		},
+		reset: func() { triggered = false },
+	})
+}
+
+// ResetAfter re-arms the trigger of s that SubscribeAfter returns, so the events are dropped again
+// until the trigger returns true. It's a no-op for other subscribers.
+func (ob *Observable) ResetAfter(s Subscriber) {
 	ob.lock.Lock()
 	defer ob.lock.Unlock()
 
-	p := NewPipe()
+	if p, has := ob.subscribers[s]; has && p.reset != nil {
+		p.reset()
+	}
+}
+
+// SubscribeFilter is like Subscribe, but only the events that pred returns true for are received.
+// pred runs inside Publish, so the rejected events never reach the subscriber's buffer, which is cheaper
+// than subscribing a Filter. It unsubscribes when ctx is done.
+func (ob *Observable) SubscribeFilter(ctx context.Context, pred func(Event) bool) Subscriber {
+	return ob.subscribe(ctx, &subscriber{filter: pred})
+}
+
+// SubscribeDone is like Subscribe, but it unsubscribes when ctx is done, and the returned done channel is closed
+// after the subscriber is removed from ob and its event channel is closed.
+func (ob *Observable) SubscribeDone(ctx context.Context) (Subscriber, <-chan struct{}) {
+	p := &subscriber{}
+	s := ob.subscribe(ctx, p)
+	done := make(chan struct{})
+
+	go func() {
+		defer trackGoroutine()()
+		<-p.done
+
+		// the pipe is only finished by the ones that remove it under the lock
+		ob.lock.Lock()
+		delete(ob.subscribers, s)
+		delete(ob.draining, s)
+		ob.lock.Unlock()
+
+		close(done)
+	}()
+
+	return s, done
+}
+
+// WeakSubscription is returned by SubscribeWeak
+type WeakSubscription struct {
+	Events Subscriber
+}
+
+// SubscribeWeak is like Subscribe, but it unsubscribes when ctx is done or when the returned handle is
+// garbage collected, so the handle must be kept reachable while Events is in use.
+// When the cleanup happens depends on the garbage collector, it may never happen.
+func (ob *Observable) SubscribeWeak(ctx context.Context) *WeakSubscription {
+	s := ob.subscribe(ctx, &subscriber{})
+	w := &WeakSubscription{s}
+
+	runtime.SetFinalizer(w, func(*WeakSubscription) {
+		ob.Unsubscribe(s)
+	})
+
+	return w
+}
+
+// Puller is returned by SubscribePull
+type Puller struct {
+	s Subscriber
+}
+
+// SubscribePull is like Subscribe, but the events are pulled one by one via Puller.Next.
+// It unsubscribes when ctx is done.
+func (ob *Observable) SubscribePull(ctx context.Context) *Puller {
+	return &Puller{ob.subscribe(ctx, &subscriber{})}
+}
+
+// Next blocks until the next event, it returns false once the subscription ends or ctx is done.
+// The ctx only bounds this call, the subscription stays.
+func (p *Puller) Next(ctx context.Context) (Event, bool) {
+	select {
+	case <-ctx.Done():
+		return nil, false
+	case e, ok := <-p.s:
+		return e, ok
+	}
+}
+
+// SubscribeFunc calls fn with ctx for each event of ob, one at a time, so that fn can respect the subscription's
+// deadline or cancellation while it handles an event. It unsubscribes when ctx is done, the returned channel is
+// closed after the last call of fn returns.
+func (ob *Observable) SubscribeFunc(ctx context.Context, fn func(context.Context, Event)) <-chan struct{} {
+	s := ob.subscribe(ctx, &subscriber{})
+	done := make(chan struct{})
+
+	go func() {
+		defer trackGoroutine()()
+		defer close(done)
+		for e := range s {
+			if ctx.Err() != nil {
+				return
+			}
+			fn(ctx, e)
+		}
+	}()
+
+	return done
+}
+
+// SubscribeOrdered is like SubscribeFunc, but each call of fn is submitted as a task to exec, such as a worker pool.
+// Each task waits for the task of the previous event to finish before it calls fn, so the events are handled in
+// the publish order no matter how exec schedules the tasks, which limits the parallelism to one per subscriber.
+// The returned channel is closed after the last task finishes.
+func (ob *Observable) SubscribeOrdered(ctx context.Context, exec func(task func()), fn func(context.Context, Event)) <-chan struct{} {
+	s := ob.subscribe(ctx, &subscriber{})
+	done := make(chan struct{})
+
+	go func() {
+		defer trackGoroutine()()
+
+		prev := make(chan struct{})
+		close(prev)
+
+		for e := range s {
+			if ctx.Err() != nil {
+				break
+			}
+
+			e, wait, next := e, prev, make(chan struct{})
+			exec(func() {
+				defer close(next)
+				<-wait
+				if ctx.Err() == nil {
+					fn(ctx, e)
+				}
+			})
+			prev = next
+		}
+
+		<-prev
+		close(done)
+	}()
+
+	return done
+}
+
+// SubscribeAdaptive is like Subscribe, but the buffer of the subscriber starts with room for min events, grows under
+// a burst up to max events, and shrinks back while it drains, releasing the memory, so mostly-idle subscribers stay small.
+// The events published while the buffer holds max events are dropped, see SubscriberInfo.Dropped.
+// It unsubscribes when ctx is done.
+func (ob *Observable) SubscribeAdaptive(ctx context.Context, min, max int) Subscriber {
+	if min < 1 {
+		min = 1
+	}
+	if max < min {
+		max = min
+	}
+
+	return ob.subscribe(ctx, &subscriber{buffer: &ring{buf: make([]Event, min), min: min, max: max, adaptive: true}})
+}
+
+// SubscribeSkipToLatest is like Subscribe, but once more than threshold events are waiting for the subscriber,
+// the backlog is discarded except the latest event and the one being delivered, so a consumer that falls behind
+// jumps to the freshest state. The discarded events are counted by SubscriberInfo.Dropped.
+// A threshold below 2 is treated as 2.
+// It unsubscribes when ctx is done.
+func (ob *Observable) SubscribeSkipToLatest(ctx context.Context, threshold int) Subscriber {
+	if threshold < 2 {
+		threshold = 2
+	}
+	return ob.subscribe(ctx, &subscriber{buffer: &ring{skip: threshold}})
+}
+
+// SubscribeLatest is like Subscribe, but with the TrackLatest option the subscriber receives the last published
+// event, if any, before the live ones. With a replay option the retained events are received instead.
+// It unsubscribes when ctx is done.
+func (ob *Observable) SubscribeLatest(ctx context.Context) Subscriber {
+	return ob.subscribe(ctx, &subscriber{latest: true})
+}
+
+// subscribe p, the optional fields of p should be set by the caller
+func (ob *Observable) subscribe(ctx context.Context, p *subscriber) Subscriber {
+	ob.add(p)
+
+	if ob.onSubscribe != nil {
+		ob.onSubscribe(p.id)
+	}
+
+	if fn := ob.onUnsubscribe; fn != nil {
+		go func() {
+			defer trackGoroutine()()
+			<-p.done
+			fn(p.id, p.reason)
+		}()
+	}
+
+	if ctx.Done() != nil {
+		go func() {
+			defer trackGoroutine()()
+			select {
+			case <-ctx.Done():
+				ob.Unsubscribe(p.Events)
+				p.Stop()
+			case <-p.done:
+			}
+		}()
+	}
+
+	return p.Events
+}
+
+// receivedHook returns the function that the pipe of p calls for each received or dropped event, nil if not needed
+func (ob *Observable) receivedHook(p *subscriber) func(Event, bool) {
+	queued := ob.queue != nil
+	if ob.latency {
+		p.latency = &histogram{}
+	}
+
+	hist, clock, internal := p.latency, ob.clock, ob.observable
+	if !queued && hist == nil && ob.logger == nil {
+		return nil
+	}
+
+	return func(e Event, dropped bool) {
+		if dropped && internal.logger != nil {
+			internal.logDrop(p, 1, "deadline")
+		}
+		if hist != nil && !dropped {
+			if env, ok := e.(Envelope); ok {
+				hist.record(clock.Now().Sub(env.Time))
+			}
+		}
+		if queued {
+			internal.received(e, dropped)
+		}
+	}
+}
+
+// logDrop records that p dropped n events
+func (ob *observable) logDrop(p *subscriber, n int, reason string) {
+	ob.logger.Warn("goob: events dropped", "subscriber", p.id, "name", p.name, "reason", reason, "count", n)
+}
+
+// add p to the subscribers, or end it if ob is closed
+func (ob *Observable) add(p *subscriber) {
+	ob.lock.Lock()
+	defer ob.lock.Unlock()
+
+	ob.lastID++
+	p.id = ob.lastID
+	if p.name == "" {
+		p.name = strconv.FormatUint(p.id, 10)
+	}
+	if p.buffer == nil {
+		p.buffer = &ring{}
+	}
+	if ob.logger != nil {
+		p.buffer.drop = func(n int, reason string) { ob.logDrop(p, n, reason) }
+	}
+	if ob.mapper != nil {
+		p.mapper = ob.mapper()
+	}
+	p.Pipe = newPipe(ob.receivedHook(p), p.buffer, ob.clock)
+	p.created = time.Now()
+
+	if ob.subscribers != nil || ob.drained {
+		if ob.history != nil {
+			for _, e := range ob.history.events() {
+				p.write(e)
+			}
+		} else if p.latest && ob.hasLatest {
+			p.write(ob.latest)
+		}
+	}
 
 	if ob.subscribers == nil {
-		p.Stop()
+		if ob.drained {
+			ob.drain(p)
+		} else {
+			p.Stop()
+		}
 	} else {
+		if ob.start != nil {
+			start := ob.start
+			ob.start = nil
+			start()
+		}
 		ob.subscribers[p.Events] = p
+		ob.watchStuck()
 	}
-
-	return p.Events
 }
 
-// Unsubscribe from observable
+// Unsubscribe from observable. It's safe to race with Publish: the subscriber is removed under the lock
+// that Publish holds, and only the pipe's own goroutine sends to and closes the channel.
+// A subscriber that is draining after CloseDrain is stopped too, its undelivered events are dropped.
 func (ob *Observable) Unsubscribe(s Subscriber) {
 	ob.lock.Lock()
 	defer ob.lock.Unlock()
 	if p, has := ob.subscribers[s]; has {
 		p.Stop()
 		delete(ob.subscribers, s)
+		ob.signalQueue()
+	} else if p, has := ob.draining[s]; has {
+		p.Stop()
+		delete(ob.draining, s)
+		ob.signalQueue()
 	}
 }
 
-// Close subscribers
+// Close subscribers, including the draining ones. The events that subscribers haven't received are dropped,
+// except for the subscriptions of operators: they still receive the events published before Close, then see it
+// as the completion of ob, they emit what they have buffered and complete their own output with CloseDrain,
+// so the completion propagates through a pipeline. Cancel the ctx of the operators to abort a pipeline.
 func (ob *Observable) Close() {
 	ob.lock.Lock()
 	defer ob.lock.Unlock()
 
 	for _, p := range ob.subscribers {
-		p.Stop()
+		if p.operator {
+			ob.drain(p)
+		} else {
+			p.Stop()
+		}
+	}
+
+	for _, p := range ob.draining {
+		if !p.operator {
+			p.Stop()
+		}
 	}
 
 	ob.subscribers = nil
+	ob.signalQueue()
+	ob.unwatchStuck()
+}
+
+// CloseDrain is like Close, but subscribers will receive their buffered events before their channels are closed.
+// Subscribing after it receives the retained events, if any, before the channel is closed.
+func (ob *Observable) CloseDrain() {
+	ob.lock.Lock()
+	defer ob.lock.Unlock()
+
+	for _, p := range ob.subscribers {
+		ob.drain(p)
+	}
+
+	ob.subscribers = nil
+	ob.drained = true
+	ob.signalQueue()
+	ob.unwatchStuck()
+}
+
+// drain ends p and tracks it as draining until all its events are delivered, it must be called under the lock
+func (ob *Observable) drain(p *subscriber) {
+	p.end()
+	ob.draining[p.Events] = p
+
+	go func() {
+		defer trackGoroutine()()
+		<-p.done
+		ob.lock.Lock()
+		delete(ob.draining, p.Events)
+		ob.lock.Unlock()
+	}()
+}
+
+// CountActive is the number of subscribers that receive new events, the same as Len
+func (ob *Observable) CountActive() int {
+	return ob.Len()
+}
+
+// CountDraining is the number of subscribers that still have buffered events to deliver after CloseDrain
+func (ob *Observable) CountDraining() int {
+	ob.lock.Lock()
+	defer ob.lock.Unlock()
+	return len(ob.draining)
 }
 
 // Len of the subscribers
@@ -76,3 +726,88 @@ func (ob *Observable) Len() int {
 	defer ob.lock.Unlock()
 	return len(ob.subscribers)
 }
+
+// Closed returns true after Close or CloseDrain
+func (ob *Observable) Closed() bool {
+	ob.lock.Lock()
+	defer ob.lock.Unlock()
+	return ob.subscribers == nil
+}
+
+// Healthy returns false if ob is closed or any subscriber has more pending events than the MaxPending option
+func (ob *Observable) Healthy() bool {
+	ob.lock.Lock()
+	defer ob.lock.Unlock()
+
+	if ob.subscribers == nil {
+		return false
+	}
+
+	if ob.maxPending > 0 {
+		for _, p := range ob.subscribers {
+			if p.len() > ob.maxPending {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// Pending returns a copy of the events that are published but not yet received by s,
+// nil if s isn't a subscriber of ob.
+func (ob *Observable) Pending(s Subscriber) []Event {
+	ob.lock.Lock()
+	defer ob.lock.Unlock()
+
+	if p, has := ob.subscribers[s]; has {
+		return p.pending()
+	}
+	return nil
+}
+
+// SubscriberInfo is a snapshot of a subscriber's state
+type SubscriberInfo struct {
+	ID   uint64
+	Name string
+
+	// Pending is the number of events that are published but not yet received
+	Pending int
+
+	// Age since the subscriber subscribed
+	Age time.Duration
+
+	// Dropped is the number of events that expired before the subscriber received them, see PublishWithDeadline,
+	// that exceeded the max of SubscribeAdaptive, or that a full tier of SubscribeTiered discarded
+	Dropped int
+
+	// Capacity of the buffer of the subscriber, the number of events it can hold without growing
+	Capacity int
+
+	// Latency from publishing to receiving, see TrackLatency
+	Latency LatencyStats
+}
+
+// Subscribers returns the info of the current subscribers, ordered by subscribing time
+func (ob *Observable) Subscribers() []SubscriberInfo {
+	ob.lock.Lock()
+	defer ob.lock.Unlock()
+
+	now := time.Now()
+	list := make([]SubscriberInfo, 0, len(ob.subscribers))
+	for _, p := range ob.subscribers {
+		list = append(list, SubscriberInfo{
+			ID:       p.id,
+			Name:     p.name,
+			Pending:  p.len(),
+			Age:      now.Sub(p.created),
+			Dropped:  p.dropped() + int(atomic.LoadInt64(&p.discarded)),
+			Capacity: p.capacity(),
+			Latency:  p.latency.stats(),
+		})
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+
+	return list
+}
@@ -1,8 +1,12 @@
 package goob_test
 
 import (
+	"context"
+	"encoding/json"
+	"log/slog"
 	"math/rand"
 	"reflect"
+	"runtime"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -23,6 +27,20 @@ func eq(t *testing.T, expected, actual interface{}) {
 	}
 }
 
+// collect the events of s until it's closed
+func collect(s <-chan goob.Event) []goob.Event {
+	list := []goob.Event{}
+	for e := range s {
+		list = append(list, e)
+	}
+	return list
+}
+
+// settle waits for the pipes to update their buffers after the events are received
+func settle() {
+	time.Sleep(10 * time.Millisecond)
+}
+
 func TestNew(t *testing.T) {
 	checkLeak(t)
 
@@ -55,10 +73,10 @@ func TestUnsubscribe(t *testing.T) {
 
 	ob := goob.New()
 
-	s := ob.Subscribe()
+	s, done := ob.SubscribeDone(context.Background())
 	ob.Unsubscribe(s)
 
-	time.Sleep(10 * time.Millisecond)
+	<-done
 
 	eq(t, ob.Len(), 0)
 }
@@ -196,6 +214,25 @@ func TestMonkey(t *testing.T) {
 	eq(t, roundSize*size, int(count))
 }
 
+func TestSubscribeFilter(t *testing.T) {
+	checkLeak(t)
+
+	ob := goob.New()
+	defer ob.Close()
+
+	s := ob.SubscribeFilter(context.Background(), func(e goob.Event) bool { return e.(int)%2 == 0 })
+
+	for i := 0; i < 5; i++ {
+		ob.Publish(i)
+	}
+
+	eq(t, 0, <-s)
+	eq(t, 2, <-s)
+	eq(t, 4, <-s)
+	settle()
+	eq(t, []goob.Event{}, ob.Pending(s))
+}
+
 func BenchmarkPublish(b *testing.B) {
 	ob := goob.New()
 	defer ob.Close()
@@ -206,11 +243,64 @@ func BenchmarkPublish(b *testing.B) {
 		}
 	}()
 
+	b.ReportAllocs()
 	for i := 0; i < b.N; i++ {
 		ob.Publish(nil)
 	}
 }
 
+// BenchmarkPublishBoxed shows the allocation of converting an int into an Event, it happens at the call site
+// before Publish runs, compare with BenchmarkPublishPreboxed.
+func BenchmarkPublishBoxed(b *testing.B) {
+	ob := goob.New()
+	defer ob.Close()
+	s := ob.Subscribe()
+
+	go func() {
+		for range s {
+		}
+	}()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ob.Publish(i + 1000)
+	}
+}
+
+func BenchmarkPublishPreboxed(b *testing.B) {
+	ob := goob.New()
+	defer ob.Close()
+	s := ob.Subscribe()
+
+	go func() {
+		for range s {
+		}
+	}()
+
+	var e goob.Event = 1000
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ob.Publish(e)
+	}
+}
+
+// BenchmarkPublishSteady keeps the buffer size stable by receiving each event before the next Publish,
+// so it should report 0 B/op.
+func BenchmarkPublishSteady(b *testing.B) {
+	ob := goob.New()
+	defer ob.Close()
+	s := ob.Subscribe()
+
+	var e goob.Event = 1000
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ob.Publish(e)
+		<-s
+	}
+}
+
 func BenchmarkConsume(b *testing.B) {
 	ob := goob.New()
 	defer ob.Close()
@@ -226,3 +316,787 @@ func BenchmarkConsume(b *testing.B) {
 		<-s
 	}
 }
+
+func TestSubscribers(t *testing.T) {
+	checkLeak(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ob := goob.New()
+	defer ob.Close()
+
+	ob.Subscribe()
+	s := ob.SubscribeNamed(ctx, "slow")
+
+	ob.Publish(1)
+	ob.Publish(2)
+	<-s
+	settle()
+
+	list := ob.Subscribers()
+	eq(t, 2, len(list))
+	eq(t, "1", list[0].Name)
+	eq(t, 2, list[0].Pending)
+	eq(t, "slow", list[1].Name)
+	eq(t, 1, list[1].Pending)
+	eq(t, true, list[0].Age >= list[1].Age)
+
+	cancel()
+	for range s {
+	}
+	eq(t, 1, len(ob.Subscribers()))
+}
+
+func TestCloseDrain(t *testing.T) {
+	checkLeak(t)
+
+	ob := goob.New()
+	s := ob.Subscribe()
+
+	ob.Publish(1)
+	ob.Publish(2)
+	ob.CloseDrain()
+	ob.Publish(3)
+
+	eq(t, []goob.Event{1, 2}, collect(s))
+	eq(t, 0, ob.Len())
+
+	_, ok := <-ob.Subscribe()
+	eq(t, false, ok)
+}
+
+func TestUnsubscribeRacePublish(t *testing.T) {
+	checkLeak(t)
+
+	ob := goob.New()
+	defer ob.Close()
+
+	stop := make(chan null)
+	defer close(stop)
+	go func() {
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+				ob.Publish(i)
+			}
+		}
+	}()
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			s := ob.SubscribeNamed(ctx, "")
+
+			last := -1
+			for e := range s {
+				if e.(int) <= last {
+					t.Error("out of order", e, last)
+				}
+				last = e.(int)
+
+				if rand.Intn(100) == 0 {
+					cancel()
+				}
+			}
+			cancel()
+		}()
+	}
+	wg.Wait()
+
+	eq(t, 0, ob.Len())
+}
+
+func TestPublishBarrier(t *testing.T) {
+	checkLeak(t)
+
+	ob := goob.New()
+	defer ob.Close()
+	s := ob.Subscribe()
+
+	ob.Publish(1)
+	eq(t, 1, ob.Subscribers()[0].Pending)
+
+	ob.Publish(2)
+	eq(t, 2, ob.Subscribers()[0].Pending)
+
+	eq(t, 1, <-s)
+	eq(t, 2, <-s)
+}
+
+func TestSubscribeAfter(t *testing.T) {
+	checkLeak(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ob := goob.New()
+	defer ob.Close()
+
+	s := ob.SubscribeAfter(ctx, func(e goob.Event) bool { return e == "start" })
+
+	ob.Publish(1)
+	ob.Publish("start")
+	ob.Publish(2)
+	ob.Publish("start")
+
+	eq(t, "start", <-s)
+	eq(t, 2, <-s)
+	eq(t, "start", <-s)
+
+	cancel()
+	for range s {
+	}
+	eq(t, 0, ob.Len())
+}
+
+func TestResetAfter(t *testing.T) {
+	checkLeak(t)
+
+	ob := goob.New()
+	defer ob.Close()
+
+	s := ob.SubscribeAfter(context.Background(), func(e goob.Event) bool { return e == "start" })
+
+	ob.Publish("start")
+	ob.Publish(1)
+	ob.ResetAfter(s)
+	ob.Publish(2)
+	ob.Publish("start")
+	ob.Publish(3)
+
+	ob.ResetAfter(ob.Subscribe()) // no-op
+
+	eq(t, "start", <-s)
+	eq(t, 1, <-s)
+	eq(t, "start", <-s)
+	eq(t, 3, <-s)
+}
+
+func TestPending(t *testing.T) {
+	checkLeak(t)
+
+	ob := goob.New()
+	defer ob.Close()
+	s := ob.Subscribe()
+
+	eq(t, []goob.Event{}, ob.Pending(s))
+
+	ob.Publish(1)
+	ob.Publish(2)
+	ob.Publish(3)
+	eq(t, []goob.Event{1, 2, 3}, ob.Pending(s))
+
+	<-s
+	settle()
+	eq(t, []goob.Event{2, 3}, ob.Pending(s))
+
+	ob.Unsubscribe(s)
+	eq(t, []goob.Event(nil), ob.Pending(s))
+}
+
+func TestHealthy(t *testing.T) {
+	checkLeak(t)
+
+	ob := goob.New(goob.MaxPending(2))
+	s := ob.Subscribe()
+
+	eq(t, true, ob.Healthy())
+
+	ob.Publish(1)
+	ob.Publish(2)
+	eq(t, true, ob.Healthy())
+
+	ob.Publish(3)
+	eq(t, false, ob.Healthy())
+
+	<-s
+	settle()
+	eq(t, true, ob.Healthy())
+
+	eq(t, false, ob.Closed())
+	ob.Close()
+	eq(t, true, ob.Closed())
+	eq(t, false, ob.Healthy())
+}
+
+func TestSubscribeDone(t *testing.T) {
+	checkLeak(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ob := goob.New()
+	defer ob.Close()
+
+	s, done := ob.SubscribeDone(ctx)
+	ob.Publish(1)
+	eq(t, 1, <-s)
+
+	cancel()
+	<-done
+
+	select {
+	case _, ok := <-s:
+		eq(t, false, ok)
+	default:
+		t.Error("the channel should be closed")
+	}
+	eq(t, 0, ob.Len())
+
+	_, done = ob.SubscribeDone(context.Background())
+	ob.CloseDrain()
+	<-done
+}
+
+func BenchmarkSubscribeFilter(b *testing.B) {
+	ob := goob.New()
+	defer ob.Close()
+	s := ob.SubscribeFilter(context.Background(), func(e goob.Event) bool { return e.(int)%2 == 0 })
+
+	go func() {
+		for i := 0; i < b.N; i++ {
+			ob.Publish(i)
+		}
+	}()
+
+	for i := 0; i < b.N/2; i++ {
+		<-s
+	}
+}
+
+func BenchmarkFilter(b *testing.B) {
+	ob := goob.New()
+	defer ob.Close()
+	s := ob.Filter(context.Background(), func(e goob.Event) bool { return e.(int)%2 == 0 }).Subscribe()
+
+	go func() {
+		for i := 0; i < b.N; i++ {
+			ob.Publish(i)
+		}
+	}()
+
+	for i := 0; i < b.N/2; i++ {
+		<-s
+	}
+}
+
+func TestCountDraining(t *testing.T) {
+	checkLeak(t)
+
+	ob := goob.New()
+	s, done := ob.SubscribeDone(context.Background())
+	ob.Subscribe()
+
+	ob.Publish(1)
+	ob.Publish(2)
+	eq(t, 2, ob.CountActive())
+	eq(t, 0, ob.CountDraining())
+
+	ob.CloseDrain()
+	eq(t, 0, ob.CountActive())
+	eq(t, 2, ob.CountDraining())
+
+	eq(t, []goob.Event{1, 2}, collect(s))
+	<-done
+	eq(t, 1, ob.CountDraining())
+
+	ob.Close()
+	for ob.CountDraining() > 0 {
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSubscribeWeak(t *testing.T) {
+	checkLeak(t)
+
+	ob := goob.New()
+	defer ob.Close()
+
+	w := ob.SubscribeWeak(context.Background())
+	ob.Publish(1)
+	eq(t, 1, <-w.Events)
+	runtime.KeepAlive(w)
+
+	ob.SubscribeWeak(context.Background())
+	eq(t, 2, ob.Len())
+
+	for i := 0; ob.Len() > 0; i++ {
+		if i == 100 {
+			t.Fatal("not cleaned up")
+		}
+		runtime.GC()
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSubscribeFunc(t *testing.T) {
+	checkLeak(t)
+
+	ob := goob.New()
+	defer ob.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	started := make(chan goob.Event)
+	done := ob.SubscribeFunc(ctx, func(ctx context.Context, e goob.Event) {
+		started <- e
+		<-ctx.Done()
+	})
+
+	ob.Publish(1)
+	ob.Publish(2)
+	eq(t, 1, <-started)
+
+	cancel()
+	<-done
+
+	time.Sleep(10 * time.Millisecond)
+	eq(t, 0, ob.Len())
+}
+
+func TestLifecycleCallbacks(t *testing.T) {
+	checkLeak(t)
+
+	lock := sync.Mutex{}
+	subscribed := map[uint64]int{}
+	unsubscribed := map[uint64]int{}
+	removed := make(chan struct{}, 10)
+
+	var ob *goob.Observable
+	ob = goob.New(
+		goob.OnSubscribe(func(id uint64) {
+			ob.Len() // it's safe to call back
+			lock.Lock()
+			defer lock.Unlock()
+			subscribed[id]++
+		}),
+		goob.OnUnsubscribe(func(id uint64, _ error) {
+			ob.Len()
+			lock.Lock()
+			defer lock.Unlock()
+			unsubscribed[id]++
+			removed <- struct{}{}
+		}),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	a := ob.Subscribe()
+	ob.SubscribeNamed(ctx, "b")
+	c := ob.Subscribe()
+	ob.Publish(1)
+
+	ob.Unsubscribe(a)
+	<-removed
+	cancel()
+	<-removed
+	ob.CloseDrain()
+	eq(t, []goob.Event{1}, collect(c))
+	<-removed
+
+	ob.Subscribe() // subscribe after close
+	<-removed
+
+	lock.Lock()
+	defer lock.Unlock()
+	eq(t, map[uint64]int{1: 1, 2: 1, 3: 1, 4: 1}, subscribed)
+	eq(t, map[uint64]int{1: 1, 2: 1, 3: 1, 4: 1}, unsubscribed)
+}
+
+func TestPublishWithDeadline(t *testing.T) {
+	checkLeak(t)
+
+	ob := goob.New()
+	defer ob.Close()
+
+	fast := ob.Subscribe()
+	slow := ob.Subscribe()
+
+	ob.PublishWithDeadline(1, 20*time.Millisecond)
+	ob.Publish(2)
+	eq(t, 1, <-fast)
+	eq(t, 2, <-fast)
+
+	time.Sleep(50 * time.Millisecond)
+	eq(t, 2, <-slow)
+	settle()
+
+	list := ob.Subscribers()
+	eq(t, 0, list[0].Dropped)
+	eq(t, 1, list[1].Dropped)
+}
+
+func TestSubscribeAdaptive(t *testing.T) {
+	checkLeak(t)
+
+	ob := goob.New()
+	defer ob.Close()
+
+	s := ob.SubscribeAdaptive(context.Background(), 4, 64)
+	eq(t, 4, ob.Subscribers()[0].Capacity)
+
+	for i := 0; i < 70; i++ {
+		ob.Publish(i)
+	}
+
+	info := ob.Subscribers()[0]
+	eq(t, 64, info.Capacity)
+	eq(t, 6, info.Dropped)
+
+	for i := 0; i < 64; i++ {
+		eq(t, i, <-s)
+	}
+	settle()
+
+	eq(t, 4, ob.Subscribers()[0].Capacity)
+}
+
+func TestPublishAsync(t *testing.T) {
+	checkLeak(t)
+
+	// a queued observable blocks Publish when the handler's own subscriber is full
+	ob := goob.NewQueued(1)
+	defer ob.Close()
+
+	machine := ob.Subscribe()
+	other := ob.Subscribe()
+
+	go func() {
+		for e := range machine {
+			if n := e.(int); n < 5 {
+				ob.PublishAsync(n + 1)
+			}
+		}
+	}()
+
+	ob.Publish(0)
+	for i := 0; i <= 5; i++ {
+		eq(t, i, <-other)
+	}
+}
+
+func TestSubscribeSkipToLatest(t *testing.T) {
+	checkLeak(t)
+
+	ob := goob.New()
+	defer ob.Close()
+
+	s := ob.SubscribeSkipToLatest(context.Background(), 3)
+
+	// the consumer stalls
+	for i := 0; i < 10; i++ {
+		ob.Publish(i)
+	}
+
+	eq(t, 0, <-s)
+	eq(t, 9, <-s)
+
+	ob.Publish(10)
+	eq(t, 10, <-s)
+	settle()
+
+	eq(t, 8, ob.Subscribers()[0].Dropped)
+}
+
+func TestNoDuplicateDelivery(t *testing.T) {
+	checkLeak(t)
+
+	ob := goob.New()
+	defer ob.Close()
+
+	stop := make(chan struct{})
+	published := make(chan struct{})
+	go func() {
+		defer close(published)
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+				ob.Publish(i)
+			}
+		}
+	}()
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for round := 0; round < 10; round++ {
+				ctx, cancel := context.WithCancel(context.Background())
+				s := ob.SubscribeNamed(ctx, "")
+
+				seen := map[goob.Event]bool{}
+				last := -1
+				for e := range s {
+					if seen[e] || e.(int) <= last {
+						t.Error("duplicate or out of order", e)
+					}
+					seen[e] = true
+					last = e.(int)
+
+					if len(seen) == 50 {
+						cancel()
+					}
+				}
+				cancel()
+			}
+		}()
+	}
+	wg.Wait()
+
+	close(stop)
+	<-published
+}
+
+func TestPublishMiddleware(t *testing.T) {
+	checkLeak(t)
+
+	order := []string{}
+
+	dropNil := func(e goob.Event, next func(goob.Event)) {
+		order = append(order, "drop")
+		if e != nil {
+			next(e)
+		}
+	}
+	tag := func(e goob.Event, next func(goob.Event)) {
+		order = append(order, "tag")
+		next([]goob.Event{"tag", e})
+	}
+
+	ob := goob.New(goob.WithPublishMiddleware(dropNil), goob.WithPublishMiddleware(tag))
+	s := ob.Subscribe()
+
+	ob.Publish(nil)
+	ob.Publish(1)
+	eq(t, true, ob.PublishOnce("k", 2))
+	ob.CloseDrain()
+
+	eq(t, []goob.Event{[]goob.Event{"tag", 1}, []goob.Event{"tag", 2}}, collect(s))
+	eq(t, []string{"drop", "drop", "tag", "drop", "tag"}, order)
+}
+
+func TestPublishFrom(t *testing.T) {
+	checkLeak(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	src := goob.New()
+	defer src.Close()
+	dst := goob.New()
+	defer dst.Close()
+
+	s := dst.Subscribe()
+	dst.PublishFrom(ctx, src)
+
+	src.Publish(1)
+	src.Publish(2)
+	eq(t, 1, <-s)
+	eq(t, 2, <-s)
+
+	cancel()
+	for src.Len() > 0 {
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSubscribeLatest(t *testing.T) {
+	checkLeak(t)
+
+	ob := goob.New(goob.TrackLatest())
+	defer ob.Close()
+
+	ob.Publish(1)
+	ob.Publish(2)
+
+	s := ob.SubscribeLatest(context.Background())
+	plain := ob.Subscribe()
+	eq(t, 2, <-s)
+
+	ob.Publish(3)
+	eq(t, 3, <-s)
+	eq(t, 3, <-plain)
+
+	// without TrackLatest there's nothing to receive first
+	untracked := goob.New()
+	untracked.Publish(1)
+	s = untracked.SubscribeLatest(context.Background())
+	untracked.Publish(2)
+	eq(t, 2, <-s)
+	untracked.Close()
+}
+
+func TestSubscribePull(t *testing.T) {
+	checkLeak(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ob := goob.New()
+	defer ob.Close()
+
+	p := ob.SubscribePull(ctx)
+	ob.Publish(1)
+	ob.Publish(2)
+
+	e, ok := p.Next(context.Background())
+	eq(t, 1, e)
+	eq(t, true, ok)
+	e, ok = p.Next(context.Background())
+	eq(t, 2, e)
+	eq(t, true, ok)
+
+	short, stop := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer stop()
+	_, ok = p.Next(short)
+	eq(t, false, ok)
+
+	ob.Publish(3)
+	e, _ = p.Next(context.Background())
+	eq(t, 3, e)
+
+	cancel()
+	_, ok = p.Next(context.Background())
+	eq(t, false, ok)
+}
+
+func TestSubscribeOrdered(t *testing.T) {
+	checkLeak(t)
+
+	ob := goob.New()
+	pool := func(task func()) { go task() }
+
+	list := []goob.Event{}
+	done := ob.SubscribeOrdered(context.Background(), pool, func(_ context.Context, e goob.Event) {
+		time.Sleep(time.Duration(rand.Intn(100)) * time.Microsecond)
+		list = append(list, e)
+	})
+
+	expected := []goob.Event{}
+	for i := 0; i < 100; i++ {
+		expected = append(expected, i)
+		ob.Publish(i)
+	}
+	ob.CloseDrain()
+	<-done
+
+	eq(t, expected, list)
+}
+
+// BenchmarkPublishParallel publishes from GOMAXPROCS goroutines to several subscribers. The publishers are serialized
+// by the lock of the observable, which keeps the same order for all the subscribers, see TestPublishConcurrentOrder.
+func BenchmarkPublishParallel(b *testing.B) {
+	ob := goob.New()
+	defer ob.Close()
+
+	for i := 0; i < 8; i++ {
+		s := ob.Subscribe()
+		go func() {
+			for range s {
+			}
+		}()
+	}
+
+	var e goob.Event = 1000
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			ob.Publish(e)
+		}
+	})
+}
+
+func TestPublishConcurrentOrder(t *testing.T) {
+	checkLeak(t)
+
+	ob := goob.New()
+	a, b := ob.Subscribe(), ob.Subscribe()
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 4; i++ {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				ob.Publish(i*100 + j)
+			}
+		}()
+	}
+	wg.Wait()
+	ob.CloseDrain()
+
+	la, lb := collect(a), collect(b)
+	eq(t, 400, len(la))
+	eq(t, la, lb)
+}
+
+func TestCopyOnPublish(t *testing.T) {
+	checkLeak(t)
+
+	type state struct{ Count int }
+
+	ob := goob.New(goob.CopyOnPublish(func(e goob.Event) goob.Event {
+		c := *e.(*state)
+		return &c
+	}))
+	defer ob.Close()
+	s := ob.Subscribe()
+
+	st := &state{1}
+	ob.Publish(st)
+	st.Count = 2
+	ob.PublishAsync(st)
+	st.Count = 3
+
+	eq(t, 1, (<-s).(*state).Count)
+	eq(t, 2, (<-s).(*state).Count)
+}
+
+func TestWithLogger(t *testing.T) {
+	checkLeak(t)
+
+	out := &syncBuffer{}
+	logger := slog.New(slog.NewJSONHandler(out, nil))
+
+	ob := goob.New(goob.WithLogger(logger))
+	defer ob.Close()
+
+	s := ob.SubscribeAdaptive(context.Background(), 1, 1)
+	ob.Publish(1)
+	ob.Publish(2)
+	eq(t, 1, <-s)
+
+	var record map[string]interface{}
+	eq(t, nil, json.Unmarshal([]byte(out.String()), &record))
+	eq(t, "WARN", record["level"])
+	eq(t, "goob: events dropped", record["msg"])
+	eq(t, float64(1), record["subscriber"])
+	eq(t, "buffer full", record["reason"])
+	eq(t, float64(1), record["count"])
+}
+
+func TestSubscriberBufferShrinks(t *testing.T) {
+	checkLeak(t)
+
+	ob := goob.New()
+	defer ob.Close()
+	s := ob.Subscribe()
+
+	for i := 0; i < 1000; i++ {
+		ob.Publish(i)
+	}
+	eq(t, 1024, ob.Subscribers()[0].Capacity)
+
+	for i := 0; i < 1000; i++ {
+		<-s
+	}
+	settle()
+	eq(t, 8, ob.Subscribers()[0].Capacity)
+}
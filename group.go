@@ -0,0 +1,102 @@
+package goob
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+)
+
+// Group supervises a pipeline: the functions of Go, and the goroutines of the operators that are created with the
+// context of the group and call a user function, such as Map, Filter, MapErr, MapParallel, ConcatMap, Expand,
+// CombineLatestN, ZipN, and RunLengthEncode. The first panic of them, the first error of Go, or the first error that
+// terminates a MapErr or FilterErr in the Terminate mode cancels the context, so all the operators of the context
+// are closed, and it's returned by Wait. The Subscribe variants that take a callback aren't supervised.
+type Group struct {
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	once   sync.Once
+	err    error
+}
+
+// PanicError is the error of Group.Wait when a supervised goroutine panics
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("goob: panic: %v\n%s", e.Value, e.Stack)
+}
+
+type groupKey struct{}
+
+// NewGroup returns a group and its context that is derived from ctx, pass the context to the operators to supervise.
+// An operator joins the group once it's subscribed, so subscribe the pipeline before calling Wait.
+func NewGroup(ctx context.Context) (*Group, context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	g := &Group{cancel: cancel}
+	return g, context.WithValue(ctx, groupKey{}, g)
+}
+
+// Go runs fn in a supervised goroutine, a non-nil error or a panic of fn cancels the group
+func (g *Group) Go(fn func() error) {
+	g.wg.Add(1)
+
+	go func() {
+		defer trackGoroutine()()
+		defer g.guard(nil)
+
+		if err := fn(); err != nil {
+			g.fail(err)
+		}
+	}()
+}
+
+// Wait for all the supervised goroutines to return, then cancel the context of the group and return the first
+// error, if any.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	g.cancel()
+	return g.err
+}
+
+func (g *Group) fail(err error) {
+	g.once.Do(func() {
+		g.err = err
+		g.cancel()
+	})
+}
+
+// guard must be deferred by a supervised goroutine, it reports the panic and closes out if it's not nil
+func (g *Group) guard(out *Observable) {
+	defer g.wg.Done()
+
+	if r := recover(); r != nil {
+		g.fail(&PanicError{r, debug.Stack()})
+		if out != nil {
+			if out.logger != nil {
+				out.logger.Error("goob: panic recovered", "panic", r)
+			}
+			out.Close()
+		}
+	}
+}
+
+// supervise returns the function that the goroutine of an operator should defer with its output,
+// if ctx belongs to a Group the goroutine joins it, otherwise the function does nothing.
+func supervise(ctx context.Context) func(out *Observable) {
+	g := groupOf(ctx)
+	if g == nil {
+		return func(*Observable) {}
+	}
+
+	g.wg.Add(1)
+	return g.guard
+}
+
+// groupOf returns the Group that ctx belongs to, or nil
+func groupOf(ctx context.Context) *Group {
+	g, _ := ctx.Value(groupKey{}).(*Group)
+	return g
+}
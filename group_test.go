@@ -0,0 +1,100 @@
+package goob_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ysmood/goob"
+)
+
+func TestGroupPanic(t *testing.T) {
+	checkLeak(t)
+
+	g, ctx := goob.NewGroup(context.Background())
+
+	ob := goob.New()
+	defer ob.Close()
+
+	m := ob.Map(ctx, func(e goob.Event) goob.Event {
+		if e == 2 {
+			panic("boom")
+		}
+		return e
+	}).Subscribe()
+	f := ob.Filter(ctx, func(goob.Event) bool { return true }).Subscribe()
+
+	ob.Publish(1)
+	eq(t, 1, <-m)
+	eq(t, 1, <-f)
+
+	ob.Publish(2)
+	collect(m)
+	collect(f) // the sibling is closed via the shared context
+
+	var p *goob.PanicError
+	eq(t, true, errors.As(g.Wait(), &p))
+	eq(t, "boom", p.Value)
+}
+
+func TestGroupGo(t *testing.T) {
+	checkLeak(t)
+
+	g, ctx := goob.NewGroup(context.Background())
+	errFail := errors.New("fail")
+
+	g.Go(func() error { return errFail })
+	g.Go(func() error {
+		<-ctx.Done()
+		return nil
+	})
+
+	eq(t, errFail, g.Wait())
+}
+
+func TestGroupMapErr(t *testing.T) {
+	checkLeak(t)
+
+	g, ctx := goob.NewGroup(context.Background())
+	errFail := errors.New("fail")
+
+	ob := goob.New()
+	defer ob.Close()
+
+	m := ob.MapErr(ctx, func(e goob.Event) (goob.Event, error) {
+		if e == 2 {
+			return nil, errFail
+		}
+		return e, nil
+	}).Subscribe()
+	f := ob.Filter(ctx, func(goob.Event) bool { return true }).Subscribe()
+
+	ob.Publish(1)
+	eq(t, 1, <-m)
+	eq(t, 1, <-f)
+
+	ob.Publish(2)
+	eq(t, []goob.Event{errFail}, collect(m))
+	collect(f) // the sibling is closed via the shared context
+
+	eq(t, errFail, g.Wait())
+}
+
+func TestGroupPanicDerived(t *testing.T) {
+	checkLeak(t)
+
+	g, ctx := goob.NewGroup(context.Background())
+
+	ob := goob.New()
+	defer ob.Close()
+
+	c := ob.ConcatMap(ctx, func(goob.Event) *goob.Observable { panic("project") }).Subscribe()
+	p := ob.MapParallel(ctx, 2, func(goob.Event) goob.Event { panic("worker") }).Subscribe()
+
+	ob.Publish(1)
+	collect(c)
+	collect(p)
+
+	var pe *goob.PanicError
+	eq(t, true, errors.As(g.Wait(), &pe))
+}
@@ -0,0 +1,97 @@
+package goob
+
+import (
+	"math/bits"
+	"sync"
+	"time"
+)
+
+// TrackLatency records, for each subscriber, the time from publishing an Envelope to the subscriber receiving it,
+// see SubscriberInfo.Latency. It needs WithEnvelope, the other events aren't measured.
+func TrackLatency() Option {
+	return func(ob *observable) {
+		ob.latency = true
+	}
+}
+
+// LatencyStats are the percentiles of the recorded latencies, they are accurate to within 1/8 of the value
+type LatencyStats struct {
+	Count         int
+	P50, P95, P99 time.Duration
+}
+
+// histogram of durations with log-linear buckets: each power of 2 is split into 8 sub-buckets,
+// so the memory is fixed and the relative error is bounded, like a lightweight HDR histogram.
+type histogram struct {
+	lock    sync.Mutex
+	count   int
+	buckets [64 * histogramSub]int
+}
+
+const histogramShift = 3
+const histogramSub = 1 << histogramShift
+
+func (h *histogram) record(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.count++
+	h.buckets[histogramBucket(uint64(d))]++
+}
+
+func histogramBucket(v uint64) int {
+	if v < histogramSub {
+		return int(v)
+	}
+	exp := bits.Len64(v) - 1 - histogramShift
+	return (exp+1)*histogramSub + int(v>>exp) - histogramSub
+}
+
+// histogramUpper is the largest value of the bucket
+func histogramUpper(b int) uint64 {
+	if b < histogramSub {
+		return uint64(b)
+	}
+	exp := b/histogramSub - 1
+	return (uint64(b%histogramSub+histogramSub)+1)<<exp - 1
+}
+
+func (h *histogram) stats() LatencyStats {
+	if h == nil {
+		return LatencyStats{}
+	}
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	return LatencyStats{
+		Count: h.count,
+		P50:   h.percentile(0.50),
+		P95:   h.percentile(0.95),
+		P99:   h.percentile(0.99),
+	}
+}
+
+func (h *histogram) percentile(p float64) time.Duration {
+	if h.count == 0 {
+		return 0
+	}
+
+	rank := int(p*float64(h.count) + 0.5)
+	if rank < 1 {
+		rank = 1
+	}
+
+	seen := 0
+	for b, n := range h.buckets {
+		seen += n
+		if seen >= rank {
+			return time.Duration(histogramUpper(b))
+		}
+	}
+	return 0
+}
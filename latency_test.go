@@ -0,0 +1,38 @@
+package goob_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ysmood/goob"
+)
+
+func TestTrackLatency(t *testing.T) {
+	checkLeak(t)
+
+	clock := goob.NewTestClock()
+	ob := goob.New(goob.WithEnvelope(), goob.WithClock(clock), goob.TrackLatency())
+	defer ob.Close()
+
+	fast := ob.Subscribe()
+	slow := ob.Subscribe()
+
+	for i := 0; i < 100; i++ {
+		ob.Publish(i)
+		<-fast
+	}
+
+	clock.Advance(100 * time.Millisecond)
+	for i := 0; i < 100; i++ {
+		<-slow
+	}
+	settle()
+
+	list := ob.Subscribers()
+	eq(t, goob.LatencyStats{Count: 100}, list[0].Latency)
+
+	l := list[1].Latency
+	eq(t, 100, l.Count)
+	eq(t, true, l.P99 >= 100*time.Millisecond && l.P99 < 113*time.Millisecond)
+	eq(t, l.P50, l.P99)
+}
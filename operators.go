@@ -0,0 +1,906 @@
+package goob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// lazy creates an observable that calls start with itself before its first subscriber is added,
+// so the events that start publishes won't be missed by the first subscriber.
+func lazy(start func(ob *Observable)) *Observable {
+	ob := New()
+
+	// start only holds a copy of ob, so ob can be garbage collected while start's goroutines are running
+	internal := &Observable{ob.observable}
+	ob.start = func() { start(internal) }
+
+	if Debug {
+		track(ob)
+	}
+
+	return ob
+}
+
+// derive is like lazy, but the returned observable inherits the options of ob that operators use, such as the clock
+func (ob *Observable) derive(start func(out *Observable)) *Observable {
+	out := lazy(start)
+	out.clock = ob.clock
+	out.errorMode = ob.errorMode
+	out.equal = ob.equal
+	out.logger = ob.logger
+	return out
+}
+
+// operate calls fn with each event of ob in a dedicated goroutine, fn emits via out.
+// The returned observable completes once ob completes or fn returns false, or is closed once ctx is done.
+func (ob *Observable) operate(ctx context.Context, fn func(out *Observable, e Event) bool) *Observable {
+	return ob.derive(func(out *Observable) {
+		s := ob.follow()
+		guard := supervise(ctx)
+
+		go func() {
+			defer trackGoroutine()()
+			defer ob.Unsubscribe(s)
+			defer guard(out)
+
+			for {
+				select {
+				case <-ctx.Done():
+					out.Close()
+					return
+				case e, ok := <-s:
+					if !ok || !fn(out, e) {
+						ob.Unsubscribe(s)
+						out.CloseDrain()
+						return
+					}
+				}
+			}
+		}()
+	})
+}
+
+// subscription of an observable
+type subscription struct {
+	ob *Observable
+	s  Subscriber
+}
+
+func (s subscription) unsubscribe() {
+	s.ob.Unsubscribe(s.s)
+}
+
+// Expand emits the events of ob, and recursively the events of the observable that project returns
+// for each emitted event, in breadth-first order. project can return nil to stop the expansion.
+// Once limit events are emitted the returned observable completes, limit <= 0 means no limit.
+// The returned observable completes once ob and all the projected observables complete.
+func (ob *Observable) Expand(ctx context.Context, limit int, project func(Event) *Observable) *Observable {
+	return ob.derive(func(out *Observable) {
+		s := ob.follow()
+		guard := supervise(ctx)
+
+		go func() {
+			defer trackGoroutine()()
+			defer guard(out)
+			queue := []subscription{}
+			count := 0
+
+			defer func() {
+				ob.Unsubscribe(s)
+				for _, i := range queue {
+					i.unsubscribe()
+				}
+			}()
+
+			// emit e and returns false if the limit is reached
+			emit := func(e Event) bool {
+				out.Publish(e)
+
+				count++
+				if limit > 0 && count >= limit {
+					return false
+				}
+
+				if p := project(e); p != nil {
+					queue = append(queue, subscription{p, p.follow()})
+				}
+				return true
+			}
+
+			src := s
+			for src != nil || len(queue) > 0 {
+				var head Subscriber
+				if len(queue) > 0 {
+					head = queue[0].s
+				}
+
+				select {
+				case <-ctx.Done():
+					out.Close()
+					return
+
+				case e, ok := <-src:
+					if !ok {
+						src = nil
+						continue
+					}
+					if !emit(e) {
+						out.CloseDrain()
+						return
+					}
+
+				case e, ok := <-head:
+					if !ok {
+						queue = queue[1:]
+						continue
+					}
+					if !emit(e) {
+						out.CloseDrain()
+						return
+					}
+				}
+			}
+
+			out.CloseDrain()
+		}()
+	})
+}
+
+// ConcatMap emits the events of the observables that project returns for each event of ob, one observable
+// after another in the order of ob. project is called for the next event, and its observable is subscribed,
+// only after the previous projected observable completes, the events of ob are buffered meanwhile.
+// A nil projection is skipped. The returned observable completes once ob and all the projected observables complete.
+func (ob *Observable) ConcatMap(ctx context.Context, project func(Event) *Observable) *Observable {
+	return ob.derive(func(out *Observable) {
+		s := ob.follow()
+		guard := supervise(ctx)
+
+		go func() {
+			defer trackGoroutine()()
+			defer guard(out)
+			queue := []Event{}
+			var active *subscription
+
+			defer func() {
+				ob.Unsubscribe(s)
+				if active != nil {
+					active.unsubscribe()
+				}
+			}()
+
+			src := s
+			for {
+				for active == nil && len(queue) > 0 {
+					p := project(queue[0])
+					queue = queue[1:]
+					if p != nil {
+						active = &subscription{p, p.follow()}
+					}
+				}
+
+				if src == nil && active == nil {
+					break
+				}
+
+				var inner Subscriber
+				if active != nil {
+					inner = active.s
+				}
+
+				select {
+				case <-ctx.Done():
+					out.Close()
+					return
+
+				case e, ok := <-src:
+					if !ok {
+						src = nil
+						continue
+					}
+					queue = append(queue, e)
+
+				case e, ok := <-inner:
+					if !ok {
+						active = nil
+						continue
+					}
+					out.Publish(e)
+				}
+			}
+
+			out.CloseDrain()
+		}()
+	})
+}
+
+// ExhaustMap emits the events of the observable that project returns for an event of ob, the events of ob
+// that arrive before the projected observable completes are dropped. A nil projection means there's nothing
+// to exhaust, so the next event of ob is projected. The returned observable completes once ob and the active projected observable complete.
+func (ob *Observable) ExhaustMap(ctx context.Context, project func(Event) *Observable) *Observable {
+	return ob.derive(func(out *Observable) {
+		s := ob.follow()
+		guard := supervise(ctx)
+
+		go func() {
+			defer trackGoroutine()()
+			defer guard(out)
+			var active *subscription
+
+			defer func() {
+				ob.Unsubscribe(s)
+				if active != nil {
+					active.unsubscribe()
+				}
+			}()
+
+			src := s
+			for src != nil || active != nil {
+				var inner Subscriber
+				if active != nil {
+					inner = active.s
+				}
+
+				select {
+				case <-ctx.Done():
+					out.Close()
+					return
+
+				case e, ok := <-src:
+					if !ok {
+						src = nil
+						continue
+					}
+					if active == nil {
+						if p := project(e); p != nil {
+							active = &subscription{p, p.follow()}
+						}
+					}
+
+				case e, ok := <-inner:
+					if !ok {
+						active = nil
+						continue
+					}
+					out.Publish(e)
+				}
+			}
+
+			out.CloseDrain()
+		}()
+	})
+}
+
+// Map emits the result of fn for each event of ob
+func (ob *Observable) Map(ctx context.Context, fn func(Event) Event) *Observable {
+	return ob.operate(ctx, func(out *Observable, e Event) bool {
+		out.Publish(fn(e))
+		return true
+	})
+}
+
+// Filter emits the events of ob that fn returns true for
+func (ob *Observable) Filter(ctx context.Context, fn func(Event) bool) *Observable {
+	return ob.operate(ctx, func(out *Observable, e Event) bool {
+		if fn(e) {
+			out.Publish(e)
+		}
+		return true
+	})
+}
+
+// DistinctUntilKeyChanged emits the events of ob whose key differs from the key of the previous event of ob,
+// the keys are compared by the default of Equality.
+func (ob *Observable) DistinctUntilKeyChanged(ctx context.Context, key func(Event) Event) *Observable {
+	equal := ob.equality(nil)
+	var last Event
+	has := false
+
+	return ob.operate(ctx, func(out *Observable, e Event) bool {
+		k := key(e)
+		if !has || !equal(last, k) {
+			out.Publish(e)
+		}
+		last, has = k, true
+		return true
+	})
+}
+
+// Diff emits the first event of ob as it is, then for each following event the result of diff with the previous
+// event and the event.
+func (ob *Observable) Diff(ctx context.Context, diff func(prev, curr Event) Event) *Observable {
+	var prev Event
+	has := false
+
+	return ob.operate(ctx, func(out *Observable, e Event) bool {
+		if has {
+			out.Publish(diff(prev, e))
+		} else {
+			out.Publish(e)
+		}
+		prev, has = e, true
+		return true
+	})
+}
+
+// Take emits the first n events of ob then completes
+func (ob *Observable) Take(ctx context.Context, n int) *Observable {
+	return ob.derive(func(out *Observable) {
+		s := ob.follow()
+
+		go func() {
+			defer trackGoroutine()()
+			defer ob.Unsubscribe(s)
+
+			for i := 0; i < n; i++ {
+				select {
+				case <-ctx.Done():
+					out.Close()
+					return
+				case e, ok := <-s:
+					if !ok {
+						out.CloseDrain()
+						return
+					}
+					out.Publish(e)
+				}
+			}
+
+			ob.Unsubscribe(s)
+			out.CloseDrain()
+		}()
+	})
+}
+
+// TakeLast emits the last n events of ob, in order, once ob completes, then completes.
+// Nothing is emitted before ob completes, and nothing at all if ctx is done first.
+func (ob *Observable) TakeLast(ctx context.Context, n int) *Observable {
+	return ob.derive(func(out *Observable) {
+		s := ob.follow()
+
+		go func() {
+			defer trackGoroutine()()
+			defer ob.Unsubscribe(s)
+
+			list := LastN(ctx, s, n)
+			if ctx.Err() != nil {
+				out.Close()
+				return
+			}
+
+			for _, e := range list {
+				out.Publish(e)
+			}
+			out.CloseDrain()
+		}()
+	})
+}
+
+// Indexed event of Enumerate
+type Indexed struct {
+	Index int
+	Value Event
+}
+
+// Enumerate emits each event of ob as an Indexed. Each subscriber of the returned observable has its own index,
+// which starts from 0 once it subscribes and counts the events it receives.
+func (ob *Observable) Enumerate(ctx context.Context) *Observable {
+	out := ob.operate(ctx, func(out *Observable, e Event) bool {
+		out.Publish(e)
+		return true
+	})
+
+	// the transform runs under the lock of out
+	out.mapper = func() func(Event) Event {
+		i := -1
+		return func(e Event) Event {
+			i++
+			return Indexed{i, e}
+		}
+	}
+
+	return out
+}
+
+// WithResource opens a resource once the returned observable is subscribed, calls use with it for each event of ob,
+// then emits the event. The resource is closed exactly once after ob completes or ctx is done.
+// If open fails its error is emitted and the returned observable completes.
+func (ob *Observable) WithResource(ctx context.Context, open func() (io.Closer, error), use func(io.Closer, Event)) *Observable {
+	return ob.derive(func(out *Observable) {
+		s := ob.follow()
+		guard := supervise(ctx)
+
+		go func() {
+			defer trackGoroutine()()
+			defer guard(out)
+			defer ob.Unsubscribe(s)
+
+			r, err := open()
+			if err != nil {
+				out.Publish(err)
+				out.CloseDrain()
+				return
+			}
+			defer func() { _ = r.Close() }()
+
+			for {
+				select {
+				case <-ctx.Done():
+					out.Close()
+					return
+				case e, ok := <-s:
+					if !ok {
+						out.CloseDrain()
+						return
+					}
+					use(r, e)
+					out.Publish(e)
+				}
+			}
+		}()
+	})
+}
+
+// Tee subscribes ob once and mirrors each of its events onto both a and b, each has its own subscribers and buffers.
+// The subscription starts once either a or b is subscribed, the events before the other one is subscribed
+// are only received by the first. Both complete once ob completes, or are closed once ctx is done.
+func (ob *Observable) Tee(ctx context.Context) (a, b *Observable) {
+	var once sync.Once
+	var outs [2]*Observable
+
+	start := func() {
+		once.Do(func() {
+			s := ob.follow()
+
+			go func() {
+				defer trackGoroutine()()
+				defer ob.Unsubscribe(s)
+
+				for {
+					select {
+					case <-ctx.Done():
+						outs[0].Close()
+						outs[1].Close()
+						return
+					case e, ok := <-s:
+						if !ok {
+							outs[0].CloseDrain()
+							outs[1].CloseDrain()
+							return
+						}
+						outs[0].Publish(e)
+						outs[1].Publish(e)
+					}
+				}
+			}()
+		})
+	}
+
+	a = ob.derive(func(out *Observable) { start() })
+	b = ob.derive(func(out *Observable) { start() })
+	outs = [2]*Observable{{a.observable}, {b.observable}}
+
+	return a, b
+}
+
+// FanOut distributes the events of ob across n channels in round-robin, each event goes to exactly one of them,
+// unlike Subscribe which broadcasts every event, and unlike Route which picks the target by the event.
+// A channel that isn't received holds back the following events of all of them, the events wait in the buffer
+// of the subscription. The channels are closed once ob completes or ctx is done. An n below 1 is treated as 1.
+func (ob *Observable) FanOut(ctx context.Context, n int) []<-chan Event {
+	if n < 1 {
+		n = 1
+	}
+
+	chans := make([]chan Event, n)
+	list := make([]<-chan Event, n)
+	for i := range chans {
+		chans[i] = make(chan Event)
+		list[i] = chans[i]
+	}
+
+	s := ob.subscribe(ctx, &subscriber{})
+
+	go func() {
+		defer trackGoroutine()()
+		defer func() {
+			for _, c := range chans {
+				close(c)
+			}
+		}()
+
+		for i := 0; ; i = (i + 1) % n {
+			select {
+			case <-ctx.Done():
+				return
+			case e, ok := <-s:
+				if !ok {
+					return
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case chans[i] <- e:
+				}
+			}
+		}
+	}()
+
+	return list
+}
+
+// Route of Observable.Route, a nil Match matches all events, so it can be the last route as the default
+type Route struct {
+	Match  func(Event) bool
+	Target *Observable
+}
+
+// Route publishes each event of ob to the Target of the first route that matches it, so an event goes to one
+// target at most. The returned observable emits the events that no route matches, the routing starts once it's
+// subscribed. It completes once ob completes, the targets are left open.
+func (ob *Observable) Route(ctx context.Context, routes []Route) *Observable {
+	return ob.operate(ctx, func(out *Observable, e Event) bool {
+		for _, r := range routes {
+			if r.Match == nil || r.Match(e) {
+				r.Target.Publish(e)
+				return true
+			}
+		}
+		out.Publish(e)
+		return true
+	})
+}
+
+// ErrNotSlice is the error of FlattenSlices in strict mode for an event that isn't a []Event
+var ErrNotSlice = errors.New("goob: the event isn't a []Event")
+
+// FlattenSlices emits each element of the events of ob that are []Event, one by one. The other events are emitted
+// as they are, or if strict is true they are ErrNotSlice errors that the ErrorPolicy of ob handles.
+func (ob *Observable) FlattenSlices(ctx context.Context, strict bool) *Observable {
+	return ob.operate(ctx, func(out *Observable, e Event) bool {
+		list, ok := e.([]Event)
+		if !ok {
+			if strict {
+				return out.handleErr(ctx, ErrNotSlice)
+			}
+			out.Publish(e)
+			return true
+		}
+
+		for _, item := range list {
+			out.Publish(item)
+		}
+		return true
+	})
+}
+
+// Operator creates an observable from ob
+type Operator func(ctx context.Context, ob *Observable) *Observable
+
+// Pipe applies the ops from left to right, each op receives the observable that the previous one returns
+func (ob *Observable) Pipe(ctx context.Context, ops ...Operator) *Observable {
+	for _, op := range ops {
+		ob = op(ctx, ob)
+	}
+	return ob
+}
+
+// MapOp is the Operator of Map
+func MapOp(fn func(Event) Event) Operator {
+	return func(ctx context.Context, ob *Observable) *Observable {
+		return ob.Map(ctx, fn)
+	}
+}
+
+// FilterOp is the Operator of Filter
+func FilterOp(fn func(Event) bool) Operator {
+	return func(ctx context.Context, ob *Observable) *Observable {
+		return ob.Filter(ctx, fn)
+	}
+}
+
+// TakeOp is the Operator of Take
+func TakeOp(n int) Operator {
+	return func(ctx context.Context, ob *Observable) *Observable {
+		return ob.Take(ctx, n)
+	}
+}
+
+// MapParallel is like Map, but fn runs on n concurrent workers, the results are still emitted in the order of ob.
+// The results that are ready are buffered until all the previous ones are emitted, so a slow event blocks
+// the emitting, while the buffer keeps growing as the other workers keep consuming ob.
+func (ob *Observable) MapParallel(ctx context.Context, n int, fn func(Event) Event) *Observable {
+	if n < 1 {
+		n = 1
+	}
+
+	type job struct {
+		i int
+		e Event
+	}
+
+	return ob.derive(func(out *Observable) {
+		s := ob.follow()
+		jobs := make(chan job)
+		results := make(chan job)
+
+		go func() {
+			defer trackGoroutine()()
+			defer close(jobs)
+			defer ob.Unsubscribe(s)
+
+			for i := 0; ; i++ {
+				select {
+				case <-ctx.Done():
+					return
+				case e, ok := <-s:
+					if !ok {
+						return
+					}
+					select {
+					case <-ctx.Done():
+						return
+					case jobs <- job{i, e}:
+					}
+				}
+			}
+		}()
+
+		wg := sync.WaitGroup{}
+		wg.Add(n)
+		for i := 0; i < n; i++ {
+			guard := supervise(ctx)
+			go func() {
+				defer trackGoroutine()()
+				defer guard(out)
+				defer wg.Done()
+
+				for j := range jobs {
+					r := job{j.i, fn(j.e)}
+					select {
+					case <-ctx.Done():
+						return
+					case results <- r:
+					}
+				}
+			}()
+		}
+
+		go func() {
+			defer trackGoroutine()()
+			wg.Wait()
+			close(results)
+		}()
+
+		go func() {
+			defer trackGoroutine()()
+			ready := map[int]Event{}
+			next := 0
+
+			for r := range results {
+				ready[r.i] = r.e
+				for {
+					e, has := ready[next]
+					if !has {
+						break
+					}
+					delete(ready, next)
+					out.Publish(e)
+					next++
+				}
+			}
+
+			if ctx.Err() == nil {
+				out.CloseDrain()
+			} else {
+				out.Close()
+			}
+		}()
+	})
+}
+
+// ErrorMode decides what MapErr and FilterErr do when their callback returns an error, see ErrorPolicy
+type ErrorMode int
+
+const (
+	// Terminate publishes the error as the last event then completes the stream, it's the default
+	Terminate ErrorMode = iota
+
+	// Continue skips the event that fails
+	Continue
+)
+
+// MapErr is like Map, but when fn returns an error the ErrorPolicy of ob decides what to do
+func (ob *Observable) MapErr(ctx context.Context, fn func(Event) (Event, error)) *Observable {
+	return ob.operate(ctx, func(out *Observable, e Event) bool {
+		r, err := fn(e)
+		if err != nil {
+			return out.handleErr(ctx, err)
+		}
+		out.Publish(r)
+		return true
+	})
+}
+
+// FilterErr is like Filter, but when fn returns an error the ErrorPolicy of ob decides what to do
+func (ob *Observable) FilterErr(ctx context.Context, fn func(Event) (bool, error)) *Observable {
+	return ob.operate(ctx, func(out *Observable, e Event) bool {
+		ok, err := fn(e)
+		if err != nil {
+			return out.handleErr(ctx, err)
+		}
+		if ok {
+			out.Publish(e)
+		}
+		return true
+	})
+}
+
+// handleErr applies the error mode on err, returns false if the stream should be terminated.
+// The terminating err also fails the Group of ctx, if any.
+func (ob *Observable) handleErr(ctx context.Context, err error) bool {
+	if ob.errorMode == Continue {
+		return true
+	}
+	ob.Publish(err)
+	if g := groupOf(ctx); g != nil {
+		g.fail(err)
+	}
+	return false
+}
+
+// Switch emits the events of the latest *Observable that source emits, the previous one is unsubscribed
+// once a new one arrives. The events of source that aren't *Observable are ignored.
+// The returned observable completes once source and the latest inner observable complete.
+func Switch(ctx context.Context, source *Observable) *Observable {
+	return source.derive(func(out *Observable) {
+		s := source.follow()
+
+		go func() {
+			defer trackGoroutine()()
+			var active *subscription
+
+			defer func() {
+				source.Unsubscribe(s)
+				if active != nil {
+					active.unsubscribe()
+				}
+			}()
+
+			src := s
+			for src != nil || active != nil {
+				var inner Subscriber
+				if active != nil {
+					inner = active.s
+				}
+
+				select {
+				case <-ctx.Done():
+					out.Close()
+					return
+
+				case e, ok := <-src:
+					if !ok {
+						src = nil
+						continue
+					}
+					if ob, ok := e.(*Observable); ok {
+						if active != nil {
+							active.unsubscribe()
+						}
+						active = &subscription{ob, ob.follow()}
+					}
+
+				case e, ok := <-inner:
+					if !ok {
+						active = nil
+						continue
+					}
+					out.Publish(e)
+				}
+			}
+
+			out.CloseDrain()
+		}()
+	})
+}
+
+// CircuitBreaker forwards the events of ob, events of type error are failures. After threshold consecutive failures
+// the circuit opens and all events are dropped for cooldown, then it's half-open: the next event is forwarded as a trial,
+// if it's a failure the circuit opens again, otherwise it closes.
+func (ob *Observable) CircuitBreaker(ctx context.Context, threshold int, cooldown time.Duration) *Observable {
+	failures := 0
+	open := false
+	var until time.Time
+
+	return ob.operate(ctx, func(out *Observable, e Event) bool {
+		if open {
+			if ob.clock.Now().Before(until) {
+				return true
+			}
+			// half-open, let one event through as the trial
+			open = false
+			failures = threshold - 1
+		}
+
+		if _, isErr := e.(error); isErr {
+			failures++
+			if failures >= threshold {
+				open = true
+				until = ob.clock.Now().Add(cooldown)
+			}
+		} else {
+			failures = 0
+		}
+
+		out.Publish(e)
+		return true
+	})
+}
+
+// equality returns eq, or the default of Equality if eq is nil
+func (ob *observable) equality(eq func(a, b Event) bool) func(a, b Event) bool {
+	if eq != nil {
+		return eq
+	}
+	if ob.equal != nil {
+		return ob.equal
+	}
+	return func(a, b Event) bool { return reflect.DeepEqual(a, b) }
+}
+
+// Run of equal events, see RunLengthEncode
+type Run struct {
+	Value Event
+	Count int
+}
+
+// RunLengthEncode collapses each run of consecutive events of ob that eq reports as equal into a Run,
+// which is emitted once a different event arrives. The last run is emitted when ob completes or ctx is done.
+// If eq is nil the default of Equality is used.
+func (ob *Observable) RunLengthEncode(ctx context.Context, eq func(a, b Event) bool) *Observable {
+	eq = ob.equality(eq)
+
+	return ob.derive(func(out *Observable) {
+		s := ob.follow()
+		guard := supervise(ctx)
+
+		go func() {
+			defer trackGoroutine()()
+			defer guard(out)
+			defer ob.Unsubscribe(s)
+
+			var run *Run
+			flush := func() {
+				if run != nil {
+					out.Publish(*run)
+				}
+			}
+
+			for {
+				select {
+				case <-ctx.Done():
+					flush()
+					out.CloseDrain()
+					return
+
+				case e, ok := <-s:
+					if !ok {
+						flush()
+						out.CloseDrain()
+						return
+					}
+					if run != nil && eq(run.Value, e) {
+						run.Count++
+						continue
+					}
+					flush()
+					run = &Run{e, 1}
+				}
+			}
+		}()
+	})
+}
@@ -0,0 +1,634 @@
+package goob_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ysmood/goob"
+)
+
+func TestExpand(t *testing.T) {
+	checkLeak(t)
+
+	ctx := context.Background()
+
+	decrement := func(e goob.Event) *goob.Observable {
+		n := e.(int)
+		if n == 0 {
+			return nil
+		}
+		return goob.FromSlice([]goob.Event{n - 1})
+	}
+
+	s := goob.FromSlice([]goob.Event{3}).Expand(ctx, 0, decrement).Subscribe()
+	eq(t, []goob.Event{3, 2, 1, 0}, collect(s))
+
+	// breadth-first
+	split := func(e goob.Event) *goob.Observable {
+		n := e.(int)
+		if n == 0 {
+			return nil
+		}
+		return goob.FromSlice([]goob.Event{n - 1, n - 1})
+	}
+
+	s = goob.FromSlice([]goob.Event{2}).Expand(ctx, 0, split).Subscribe()
+	eq(t, []goob.Event{2, 1, 1, 0, 0, 0, 0}, collect(s))
+}
+
+func TestExpandLimit(t *testing.T) {
+	checkLeak(t)
+
+	ctx := context.Background()
+
+	increment := func(e goob.Event) *goob.Observable {
+		return goob.FromSlice([]goob.Event{e.(int) + 1})
+	}
+
+	s := goob.FromSlice([]goob.Event{0}).Expand(ctx, 5, increment).Subscribe()
+	eq(t, []goob.Event{0, 1, 2, 3, 4}, collect(s))
+}
+
+func TestExpandCancel(t *testing.T) {
+	checkLeak(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ob := goob.New()
+	s := ob.Expand(ctx, 0, func(goob.Event) *goob.Observable { return nil }).Subscribe()
+
+	ob.Publish(1)
+	eq(t, 1, <-s)
+
+	cancel()
+	collect(s)
+}
+
+// delayed publishes the events after d then completes
+func delayed(d time.Duration, events ...goob.Event) *goob.Observable {
+	ob := goob.New()
+	go func() {
+		time.Sleep(d)
+		for _, e := range events {
+			ob.Publish(e)
+		}
+		ob.CloseDrain()
+	}()
+	return ob
+}
+
+func TestConcatMap(t *testing.T) {
+	checkLeak(t)
+
+	durations := []time.Duration{30, 0, 20, 10}
+
+	s := goob.FromSlice([]goob.Event{0, 1, 2, 3}).ConcatMap(context.Background(), func(e goob.Event) *goob.Observable {
+		i := e.(int)
+		return delayed(durations[i]*time.Millisecond, i, i*10)
+	}).Subscribe()
+
+	eq(t, []goob.Event{0, 0, 1, 10, 2, 20, 3, 30}, collect(s))
+}
+
+func TestConcatMapSequential(t *testing.T) {
+	checkLeak(t)
+
+	ctx := context.Background()
+	running := int32(0)
+
+	s := goob.FromSlice([]goob.Event{0, 1, 2, nil}).ConcatMap(ctx, func(e goob.Event) *goob.Observable {
+		if e == nil {
+			return nil
+		}
+		// the inner work only starts once it's subscribed
+		return goob.FromSlice([]goob.Event{e}).Map(ctx, func(e goob.Event) goob.Event {
+			if atomic.AddInt32(&running, 1) != 1 {
+				t.Error("projections overlap")
+			}
+			time.Sleep(10 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			return e
+		})
+	}).Subscribe()
+
+	eq(t, []goob.Event{0, 1, 2}, collect(s))
+}
+
+func TestExhaustMap(t *testing.T) {
+	checkLeak(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ob := goob.New()
+	s := ob.ExhaustMap(ctx, func(e goob.Event) *goob.Observable {
+		return delayed(10*time.Millisecond, e)
+	}).Subscribe()
+
+	ob.Publish(1)
+	ob.Publish(2)
+	eq(t, 1, <-s)
+
+	time.Sleep(10 * time.Millisecond)
+
+	ob.Publish(3)
+	eq(t, 3, <-s)
+
+	ob.CloseDrain()
+	eq(t, []goob.Event{}, collect(s))
+}
+
+func TestExhaustMapNil(t *testing.T) {
+	checkLeak(t)
+
+	s := goob.FromSlice([]goob.Event{nil, 1}).ExhaustMap(context.Background(), func(e goob.Event) *goob.Observable {
+		if e == nil {
+			return nil
+		}
+		return goob.FromSlice([]goob.Event{e})
+	}).Subscribe()
+
+	eq(t, []goob.Event{1}, collect(s))
+}
+
+func TestMapFilterTake(t *testing.T) {
+	checkLeak(t)
+
+	ctx := context.Background()
+	ob := goob.FromSlice([]goob.Event{1, 2, 3, 4})
+
+	eq(t, []goob.Event{2, 4, 6, 8}, collect(ob.Map(ctx, func(e goob.Event) goob.Event {
+		return e.(int) * 2
+	}).Subscribe()))
+
+	eq(t, []goob.Event{1, 3}, collect(ob.Filter(ctx, func(e goob.Event) bool {
+		return e.(int)%2 == 1
+	}).Subscribe()))
+
+	eq(t, []goob.Event{1, 2}, collect(ob.Take(ctx, 2).Subscribe()))
+}
+
+func TestTakeUnsubscribe(t *testing.T) {
+	checkLeak(t)
+
+	ob := goob.New()
+	defer ob.Close()
+
+	s := ob.Take(context.Background(), 1).Subscribe()
+	ob.Publish(1)
+	ob.Publish(2)
+
+	eq(t, []goob.Event{1}, collect(s))
+	eq(t, 0, ob.Len())
+}
+
+func TestMapParallel(t *testing.T) {
+	checkLeak(t)
+
+	ob := goob.FromSlice([]goob.Event{0, 1, 2, 3, 4, 5})
+
+	start := time.Now()
+	s := ob.MapParallel(context.Background(), 3, func(e goob.Event) goob.Event {
+		if e.(int) == 2 {
+			time.Sleep(50 * time.Millisecond)
+		}
+		return e.(int) * 10
+	}).Subscribe()
+
+	eq(t, 0, <-s)
+	eq(t, 10, <-s)
+	eq(t, 20, <-s)
+	eq(t, true, time.Since(start) >= 50*time.Millisecond)
+	eq(t, []goob.Event{30, 40, 50}, collect(s))
+}
+
+func TestErrorPolicy(t *testing.T) {
+	checkLeak(t)
+
+	ctx := context.Background()
+	errOdd := errors.New("odd")
+
+	half := func(e goob.Event) (goob.Event, error) {
+		if e.(int)%2 == 1 {
+			return nil, errOdd
+		}
+		return e.(int) / 2, nil
+	}
+	even := func(e goob.Event) (bool, error) {
+		if e.(int) == 3 {
+			return false, errOdd
+		}
+		return e.(int)%2 == 0, nil
+	}
+
+	run := func(mode goob.ErrorMode, op func(*goob.Observable) *goob.Observable) []goob.Event {
+		src := goob.New(goob.ErrorPolicy(mode))
+		s := op(src).Subscribe()
+		for _, e := range []int{2, 4, 3, 6} {
+			src.Publish(e)
+		}
+		src.CloseDrain()
+		return collect(s)
+	}
+
+	mapErr := func(ob *goob.Observable) *goob.Observable { return ob.MapErr(ctx, half) }
+	filterErr := func(ob *goob.Observable) *goob.Observable { return ob.FilterErr(ctx, even) }
+
+	eq(t, []goob.Event{1, 2, 3}, run(goob.Continue, mapErr))
+	eq(t, []goob.Event{1, 2, errOdd}, run(goob.Terminate, mapErr))
+	eq(t, []goob.Event{2, 4, 6}, run(goob.Continue, filterErr))
+	eq(t, []goob.Event{2, 4, errOdd}, run(goob.Terminate, filterErr))
+}
+
+func TestSwitch(t *testing.T) {
+	checkLeak(t)
+
+	source := goob.New()
+	a, b := goob.New(), goob.New()
+	s := goob.Switch(context.Background(), source).Subscribe()
+
+	source.Publish(a)
+	time.Sleep(10 * time.Millisecond)
+	a.Publish(1)
+	eq(t, 1, <-s)
+
+	source.Publish(b)
+	time.Sleep(10 * time.Millisecond)
+	eq(t, 0, a.Len())
+
+	a.Publish(2)
+	b.Publish(3)
+	eq(t, 3, <-s)
+
+	source.Publish(goob.FromSlice([]goob.Event{4, 5}))
+	source.CloseDrain()
+	eq(t, []goob.Event{4, 5}, collect(s))
+	eq(t, 0, b.Len())
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	checkLeak(t)
+
+	clock := goob.NewTestClock()
+	ob := goob.New(goob.WithClock(clock))
+	s := ob.CircuitBreaker(context.Background(), 2, time.Second).Subscribe()
+
+	errFail := errors.New("fail")
+
+	ob.Publish(errFail)
+	ob.Publish(1)
+	ob.Publish(errFail)
+	ob.Publish(errFail) // open
+	ob.Publish(2)       // dropped
+	time.Sleep(10 * time.Millisecond)
+
+	clock.Advance(time.Second)
+	ob.Publish(errFail) // half-open trial fails
+	ob.Publish(3)       // dropped
+	time.Sleep(10 * time.Millisecond)
+
+	clock.Advance(time.Second)
+	ob.Publish(4) // half-open trial succeeds
+	ob.Publish(errFail)
+	ob.Publish(5)
+	ob.CloseDrain()
+
+	eq(t, []goob.Event{errFail, 1, errFail, errFail, errFail, 4, errFail, 5}, collect(s))
+}
+
+func TestRunLengthEncode(t *testing.T) {
+	checkLeak(t)
+
+	same := func(a, b goob.Event) bool { return a == b }
+
+	s := goob.FromSlice([]goob.Event{"A", "A", "A", "B", "A", "A"}).
+		RunLengthEncode(context.Background(), same).Subscribe()
+
+	eq(t, []goob.Event{
+		goob.Run{Value: "A", Count: 3},
+		goob.Run{Value: "B", Count: 1},
+		goob.Run{Value: "A", Count: 2},
+	}, collect(s))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ob := goob.New()
+	defer ob.Close()
+
+	s = ob.RunLengthEncode(ctx, same).Subscribe()
+	ob.Publish(1)
+	ob.Publish(1)
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+
+	eq(t, []goob.Event{goob.Run{Value: 1, Count: 2}}, collect(s))
+}
+
+func TestTakeLast(t *testing.T) {
+	checkLeak(t)
+
+	ctx := context.Background()
+	ob := goob.FromSlice([]goob.Event{1, 2, 3, 4, 5})
+
+	eq(t, []goob.Event{4, 5}, collect(ob.TakeLast(ctx, 2).Subscribe()))
+	eq(t, []goob.Event{}, collect(ob.TakeLast(ctx, 0).Subscribe()))
+
+	ctx, cancel := context.WithCancel(ctx)
+	live := goob.New()
+	defer live.Close()
+
+	s := live.TakeLast(ctx, 2).Subscribe()
+	live.Publish(1)
+	cancel()
+	eq(t, []goob.Event{}, collect(s))
+}
+
+func TestEnumerate(t *testing.T) {
+	checkLeak(t)
+
+	ctx := context.Background()
+	ob := goob.New()
+
+	en := ob.Enumerate(ctx)
+	first := en.Subscribe()
+	ob.Publish("a")
+	eq(t, goob.Indexed{Index: 0, Value: "a"}, <-first)
+
+	second := en.Subscribe()
+	ob.Publish("b")
+	ob.Publish("c")
+	ob.CloseDrain()
+
+	eq(t, []goob.Event{goob.Indexed{Index: 1, Value: "b"}, goob.Indexed{Index: 2, Value: "c"}}, collect(first))
+	eq(t, []goob.Event{goob.Indexed{Index: 0, Value: "b"}, goob.Indexed{Index: 1, Value: "c"}}, collect(second))
+}
+
+type closer struct {
+	closed int32
+}
+
+func (c *closer) Close() error {
+	atomic.AddInt32(&c.closed, 1)
+	return nil
+}
+
+func TestWithResource(t *testing.T) {
+	checkLeak(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ob := goob.New()
+	defer ob.Close()
+
+	r := &closer{}
+	used := []goob.Event{}
+	s := ob.WithResource(ctx, func() (io.Closer, error) {
+		return r, nil
+	}, func(c io.Closer, e goob.Event) {
+		eq(t, r, c)
+		used = append(used, e)
+	}).Subscribe()
+
+	ob.Publish(1)
+	eq(t, 1, <-s)
+	eq(t, int32(0), atomic.LoadInt32(&r.closed))
+
+	cancel()
+	collect(s)
+	settle()
+	eq(t, int32(1), atomic.LoadInt32(&r.closed))
+	eq(t, []goob.Event{1}, used)
+}
+
+func TestWithResourceOpenErr(t *testing.T) {
+	checkLeak(t)
+
+	errOpen := errors.New("open")
+	s := goob.FromSlice([]goob.Event{1}).WithResource(context.Background(), func() (io.Closer, error) {
+		return nil, errOpen
+	}, func(io.Closer, goob.Event) {}).Subscribe()
+
+	eq(t, []goob.Event{errOpen}, collect(s))
+}
+
+func TestCompletionPropagation(t *testing.T) {
+	checkLeak(t)
+
+	ctx := context.Background()
+
+	chain := func(src *goob.Observable) goob.Subscriber {
+		return src.Map(ctx, func(e goob.Event) goob.Event {
+			return e.(int) * 10
+		}).Filter(ctx, func(e goob.Event) bool {
+			return e.(int) > 10
+		}).Debounce(ctx, time.Hour).Subscribe()
+	}
+
+	src := goob.New()
+	s := chain(src)
+	src.Publish(1)
+	src.Publish(2)
+	src.Publish(3)
+	src.CloseDrain()
+	eq(t, []goob.Event{30}, collect(s))
+
+	src = goob.New()
+	s = chain(src)
+	src.Publish(4)
+	src.Close()
+	eq(t, []goob.Event{40}, collect(s))
+
+	src = goob.New()
+	s = src.Map(context.Background(), func(e goob.Event) goob.Event { return e }).Subscribe()
+	expected := []goob.Event{}
+	for i := 0; i < 100; i++ {
+		expected = append(expected, i)
+		src.Publish(i)
+	}
+	src.Close()
+	eq(t, expected, collect(s))
+}
+
+func TestTee(t *testing.T) {
+	checkLeak(t)
+
+	subscribed := int32(0)
+	ob := goob.New(goob.OnSubscribe(func(uint64) { atomic.AddInt32(&subscribed, 1) }))
+
+	a, b := ob.Tee(context.Background())
+	sa := a.Subscribe()
+	sb := b.Subscribe()
+
+	ob.Publish(1)
+	ob.Publish(2)
+	eq(t, 1, <-sa)
+	eq(t, 2, <-sa)
+
+	ob.CloseDrain()
+	eq(t, []goob.Event{1, 2}, collect(sb))
+	eq(t, []goob.Event{}, collect(sa))
+	eq(t, int32(1), atomic.LoadInt32(&subscribed))
+}
+
+func TestRoute(t *testing.T) {
+	checkLeak(t)
+
+	ob := goob.New()
+	small, even := goob.New(), goob.New()
+	defer small.Close()
+	defer even.Close()
+
+	sSmall, sEven := small.Subscribe(), even.Subscribe()
+
+	unmatched := ob.Route(context.Background(), []goob.Route{
+		{Match: func(e goob.Event) bool { return e.(int) < 3 }, Target: small},
+		{Match: func(e goob.Event) bool { return e.(int)%2 == 0 }, Target: even},
+	}).Subscribe()
+
+	for i := 0; i < 6; i++ {
+		ob.Publish(i)
+	}
+	ob.CloseDrain()
+
+	eq(t, []goob.Event{3, 5}, collect(unmatched))
+	eq(t, 0, <-sSmall)
+	eq(t, 1, <-sSmall)
+	eq(t, 2, <-sSmall)
+	eq(t, 4, <-sEven)
+	settle()
+	eq(t, 0, len(small.Pending(sSmall))+len(even.Pending(sEven)))
+}
+
+func TestRouteDefault(t *testing.T) {
+	checkLeak(t)
+
+	fallback := goob.New()
+	defer fallback.Close()
+	s := fallback.Subscribe()
+
+	unmatched := goob.FromSlice([]goob.Event{1}).Route(context.Background(), []goob.Route{
+		{Target: fallback},
+	}).Subscribe()
+
+	eq(t, []goob.Event{}, collect(unmatched))
+	eq(t, 1, <-s)
+}
+
+func TestFlattenSlices(t *testing.T) {
+	checkLeak(t)
+
+	ctx := context.Background()
+	ob := goob.FromSlice([]goob.Event{[]goob.Event{1, 2, 3}, 4, []goob.Event{5}})
+
+	eq(t, []goob.Event{1, 2, 3, 4, 5}, collect(ob.FlattenSlices(ctx, false).Subscribe()))
+	eq(t, []goob.Event{1, 2, 3, goob.ErrNotSlice}, collect(ob.FlattenSlices(ctx, true).Subscribe()))
+
+	lenient := goob.New(goob.ErrorPolicy(goob.Continue))
+	s := lenient.FlattenSlices(ctx, true).Subscribe()
+	lenient.Publish(4)
+	lenient.Publish([]goob.Event{5})
+	lenient.CloseDrain()
+	eq(t, []goob.Event{5}, collect(s))
+}
+
+func TestEquality(t *testing.T) {
+	checkLeak(t)
+
+	type item struct {
+		ID   int
+		Name string
+	}
+	byID := func(a, b goob.Event) bool { return a.(item).ID == b.(item).ID }
+	never := func(a, b goob.Event) bool { return false }
+
+	ob := goob.New(goob.Equality(byID))
+	s := ob.RunLengthEncode(context.Background(), nil).Subscribe()
+	own := ob.RunLengthEncode(context.Background(), never).Subscribe()
+	plain := goob.New()
+	p := plain.RunLengthEncode(context.Background(), nil).Subscribe()
+
+	for _, e := range []goob.Event{item{1, "a"}, item{1, "b"}, item{2, "c"}} {
+		ob.Publish(e)
+		plain.Publish(e)
+	}
+	ob.CloseDrain()
+	plain.CloseDrain()
+
+	eq(t, []goob.Event{
+		goob.Run{Value: item{1, "a"}, Count: 2},
+		goob.Run{Value: item{2, "c"}, Count: 1},
+	}, collect(s))
+	eq(t, 3, len(collect(own)))
+	eq(t, 3, len(collect(p)))
+}
+
+func TestDistinctUntilKeyChanged(t *testing.T) {
+	checkLeak(t)
+
+	type item struct {
+		ID  int
+		Rev int
+	}
+
+	s := goob.FromSlice([]goob.Event{item{1, 1}, item{1, 2}, item{2, 1}, item{2, 2}, item{2, 3}, item{1, 3}}).
+		DistinctUntilKeyChanged(context.Background(), func(e goob.Event) goob.Event { return e.(item).ID }).
+		Subscribe()
+
+	eq(t, []goob.Event{item{1, 1}, item{2, 1}, item{1, 3}}, collect(s))
+}
+
+func TestDiff(t *testing.T) {
+	checkLeak(t)
+
+	changed := func(prev, curr goob.Event) goob.Event {
+		p, c := prev.(map[string]int), curr.(map[string]int)
+		d := map[string]int{}
+		for k, v := range c {
+			if p[k] != v {
+				d[k] = v
+			}
+		}
+		return d
+	}
+
+	s := goob.FromSlice([]goob.Event{
+		map[string]int{"a": 1, "b": 1},
+		map[string]int{"a": 2, "b": 1},
+		map[string]int{"a": 2, "b": 3},
+	}).Diff(context.Background(), changed).Subscribe()
+
+	eq(t, []goob.Event{
+		map[string]int{"a": 1, "b": 1},
+		map[string]int{"a": 2},
+		map[string]int{"b": 3},
+	}, collect(s))
+}
+
+func TestFanOut(t *testing.T) {
+	checkLeak(t)
+
+	ob := goob.New()
+	chans := ob.FanOut(context.Background(), 3)
+	for i := 0; i < 9; i++ {
+		ob.Publish(i)
+	}
+	ob.CloseDrain()
+
+	results := make([][]goob.Event, 3)
+	wg := sync.WaitGroup{}
+	for i, c := range chans {
+		i, c := i, c
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = collect(c)
+		}()
+	}
+	wg.Wait()
+
+	eq(t, [][]goob.Event{{0, 3, 6}, {1, 4, 7}, {2, 5, 8}}, results)
+}
@@ -0,0 +1,125 @@
+package goob
+
+import "log/slog"
+
+// Option of New
+type Option func(ob *observable)
+
+// MaxPending sets the max number of pending events a subscriber can have for Healthy to report true,
+// 0 means no limit.
+func MaxPending(n int) Option {
+	return func(ob *observable) {
+		ob.maxPending = n
+	}
+}
+
+// WithClock sets the clock that the time-based operators use, the default is the real clock.
+// The observables that operators return inherit it.
+func WithClock(c Clock) Option {
+	return func(ob *observable) {
+		ob.clock = c
+	}
+}
+
+// Replay retains the last n published events and replays them to new subscribers before any live event,
+// n <= 0 means all the events. The replay happens under the same lock as Publish, so with WithEnvelope a new
+// subscriber receives strictly increasing Seq values across the replay and live boundary, without duplicates or gaps.
+func Replay(n int) Option {
+	return func(ob *observable) {
+		ob.history = &fullHistory{max: n}
+	}
+}
+
+// WithEnvelope wraps each published event into an Envelope, so subscribers receive Envelope values
+func WithEnvelope() Option {
+	return func(ob *observable) {
+		ob.envelope = true
+	}
+}
+
+// WithRegistry sets the Registry that fills Envelope.TypeID, it implies WithEnvelope
+func WithRegistry(r *Registry) Option {
+	return func(ob *observable) {
+		ob.envelope = true
+		ob.registry = r
+	}
+}
+
+// WithLogger sets the logger that records why events go missing: the events a subscriber drops, the subscribers
+// StuckTimeout evicts, and the panics a Group recovers from operators. The records have the fields subscriber, name,
+// reason, and count, or panic. It's nil by default, which logs nothing. The observables that operators return
+// inherit it. The drops of a full buffer are logged under the lock of the observable, so keep the handler fast.
+func WithLogger(l *slog.Logger) Option {
+	return func(ob *observable) {
+		ob.logger = l
+	}
+}
+
+// DedupWindow sets how many recent keys PublishOnce remembers, the default is 1024
+func DedupWindow(n int) Option {
+	return func(ob *observable) {
+		ob.dedupWindow = n
+	}
+}
+
+// ErrorPolicy sets the ErrorMode of MapErr and FilterErr, the observables that operators return inherit it.
+// The error is published as an event of type error, the same as other in-band errors such as the ones
+// of FromChanFunc.
+func ErrorPolicy(mode ErrorMode) Option {
+	return func(ob *observable) {
+		ob.errorMode = mode
+	}
+}
+
+// Equality sets the default equality of the operators that compare events, such as RunLengthEncode and
+// SubscribeDedupWindow, it's used when their own eq is nil, the eq of an operator always wins.
+// Without it reflect.DeepEqual is used. The observables that operators return inherit it.
+func Equality(eq func(a, b Event) bool) Option {
+	return func(ob *observable) {
+		ob.equal = eq
+	}
+}
+
+// OnSubscribe sets fn to be called with the ID of each new subscriber, the same ID as SubscriberInfo.ID.
+// It's called outside the lock of the observable before the subscribe method returns, so fn can call the observable.
+func OnSubscribe(fn func(id uint64)) Option {
+	return func(ob *observable) {
+		ob.onSubscribe = fn
+	}
+}
+
+// OnUnsubscribe sets fn to be called once with the ID of each subscriber after its channel is closed,
+// no matter it's caused by Unsubscribe, ctx, Close, the end of CloseDrain, or an eviction. The reason is nil
+// except for an eviction, such as ErrStuck. fn runs on its own goroutine outside the lock of the observable.
+func OnUnsubscribe(fn func(id uint64, reason error)) Option {
+	return func(ob *observable) {
+		ob.onUnsubscribe = fn
+	}
+}
+
+// WithPublishMiddleware adds mw to intercept each event of Publish, PublishAsync, PublishWithDeadline, and
+// PublishOnce before it reaches the subscribers. The middlewares run in the order they are added, mw passes the
+// event, or a transformed one, to the next middleware via next, or drops it by not calling next.
+// mw runs outside the lock of the observable on the goroutine of the caller.
+func WithPublishMiddleware(mw func(e Event, next func(Event))) Option {
+	return func(ob *observable) {
+		ob.middleware = append(ob.middleware, mw)
+	}
+}
+
+// CopyOnPublish sets fn to make a copy of each event before it's published, so that a publisher can reuse or
+// mutate its value afterward while the subscribers hold an unshared snapshot. fn is called once per publish call,
+// before the middleware of WithPublishMiddleware, on the publishing goroutine.
+func CopyOnPublish(fn func(Event) Event) Option {
+	return func(ob *observable) {
+		ob.copier = fn
+	}
+}
+
+// TrackLatest remembers the last published event for SubscribeLatest, it's a lighter BehaviorSubject than Replay(1)
+// because other subscribers don't receive it.
+func TrackLatest() Option {
+	return func(ob *observable) {
+		ob.trackLatest = true
+	}
+}
@@ -2,9 +2,12 @@ package goob
 
 import (
 	"sync"
+	"time"
 )
 
-// Event interface
+// Event interface. Converting a value to an Event allocates unless the value is pointer-shaped, zero-sized,
+// or a small integer, the allocation happens at the call site of Publish and the event is never copied after it.
+// For a hot path publish pointers or reuse an Event that's already converted, see BenchmarkPublishBoxed.
 type Event interface{}
 
 // Pipe the Event via Write to Events. Events uses an internal buffer so it won't block Write.
@@ -13,21 +16,49 @@ type Pipe struct {
 	Write  func(Event)
 	Events <-chan Event
 	Stop   func()
+
+	// len of the events that are written but not yet received
+	len func() int
+
+	// pending returns a copy of the events that are written but not yet received
+	pending func() []Event
+
+	// end closes Events once all the written events are received
+	end func()
+
+	// done is closed once Events is closed
+	done <-chan struct{}
+
+	// dropped is the number of events that expired before they were received, see PublishWithDeadline,
+	// or that exceeded the max of an adaptive buffer, see SubscribeAdaptive
+	dropped func() int
+
+	// capacity of the backing array of the buffer
+	capacity func() int
+
+	// offered returns since when the front event has been waiting to be received
+	offered func() time.Time
 }
 
 // NewPipe instance
 func NewPipe() *Pipe {
+	return newPipe(nil, &ring{}, realClock{})
+}
+
+// newPipe buffers the events in buf, and calls received, if not nil, each time an event is received or dropped.
+// clock measures since when the front event is offered.
+func newPipe(received func(e Event, dropped bool), buf *ring, clock Clock) *Pipe {
 	events := make(chan Event)
 	lock := sync.Mutex{}
-	buf := []Event{}
+	ended := false
+	dropped := 0
+	var offered time.Time
 	wait := make(chan struct{}, 1)
 	stop := make(chan struct{})
+	stopOnce := sync.Once{}
+	done := make(chan struct{})
 
-	write := func(e Event) {
-		lock.Lock()
-		buf = append(buf, e)
-		lock.Unlock()
-
+	notify := func() {
 		if len(wait) == 0 {
 			select {
 			case <-stop:
@@ -37,30 +68,279 @@ func NewPipe() *Pipe {
 		}
 	}
 
+	write := func(e Event) {
+		lock.Lock()
+		if buf.len() == 0 {
+			offered = clock.Now()
+		}
+		buf.push(e)
+		lock.Unlock()
+
+		notify()
+	}
+
+	end := func() {
+		lock.Lock()
+		ended = true
+		lock.Unlock()
+
+		notify()
+	}
+
+	length := func() int {
+		lock.Lock()
+		defer lock.Unlock()
+		return buf.len()
+	}
+
+	pending := func() []Event {
+		lock.Lock()
+		defer lock.Unlock()
+		list := buf.list()
+		for i, e := range list {
+			if d, ok := e.(deadlined); ok {
+				list[i] = d.e
+			}
+		}
+		return list
+	}
+
+	offeredAt := func() time.Time {
+		lock.Lock()
+		defer lock.Unlock()
+		return offered
+	}
+
+	countDropped := func() int {
+		lock.Lock()
+		defer lock.Unlock()
+		return dropped + buf.dropped
+	}
+
+	capacity := func() int {
+		lock.Lock()
+		defer lock.Unlock()
+		return len(buf.buf)
+	}
+
 	go func() {
+		defer trackGoroutine()()
+		defer close(done)
 		defer close(events)
+		defer func() {
+			lock.Lock()
+			buf.close()
+			lock.Unlock()
+		}()
 
 		for {
 			lock.Lock()
-			section := buf
-			buf = []Event{}
+			if buf.n == 0 {
+				if ended {
+					lock.Unlock()
+					return
+				}
+				lock.Unlock()
+
+				select {
+				case <-stop:
+					return
+				case <-wait:
+				}
+				continue
+			}
+			e := buf.front()
+			offered = clock.Now()
 			lock.Unlock()
 
-			for _, e := range section {
+			var expire <-chan time.Time
+			expired := false
+			if d, ok := e.(deadlined); ok {
+				e = d.e
+				if left := d.at.Sub(d.clock.Now()); left > 0 {
+					expire = d.clock.After(left)
+				} else {
+					expired = true
+				}
+			}
+
+			if !expired {
 				select {
 				case <-stop:
 					return
 				case events <- e:
+				case <-expire:
+					expired = true
 				}
 			}
 
-			select {
-			case <-stop:
-				return
-			case <-wait:
+			// the event stays in buf until it's received, so len counts it
+			lock.Lock()
+			buf.pop()
+			if expired {
+				dropped++
+			}
+			lock.Unlock()
+
+			if received != nil {
+				received(e, expired)
 			}
 		}
 	}()
 
-	return &Pipe{write, events, func() { close(stop) }}
+	return &Pipe{
+		Write:    write,
+		Events:   events,
+		Stop:     func() { stopOnce.Do(func() { close(stop) }) },
+		len:      length,
+		pending:  pending,
+		end:      end,
+		done:     done,
+		dropped:  countDropped,
+		capacity: capacity,
+		offered:  offeredAt,
+	}
+}
+
+// deadlined is an event that's dropped if it isn't received before at
+type deadlined struct {
+	e     Event
+	at    time.Time
+	clock Clock
+}
+
+// ringSize is the initial size of the backing array of a ring, it never shrinks below it unless it's adaptive
+const ringSize = 8
+
+// ring is a FIFO queue that reuses its backing array, so it doesn't allocate once its size stabilizes
+type ring struct {
+	buf  []Event
+	head int
+	n    int
+
+	// the options of an adaptive ring, see SubscribeAdaptive. If max > 0 push drops the events beyond max.
+	// The backing array is halved once at most a quarter of it is used, but never below min if adaptive,
+	// otherwise below ringSize.
+	min, max int
+	adaptive bool
+	dropped  int
+
+	// skip > 0 discards the backlog behind the front event once it exceeds skip events, see SubscribeSkipToLatest
+	skip int
+
+	// spill the events to disk once the ring is full, see SubscribeSpill
+	spill *spill
+
+	// drop is called with the number of dropped events and the reason, see WithLogger
+	drop func(n int, reason string)
+}
+
+func (r *ring) push(e Event) {
+	if r.spill != nil && (r.spill.count > 0 || r.n >= r.spill.memCap) {
+		if !r.spill.write(e) {
+			r.discard(1, "encode")
+		}
+		return
+	}
+
+	if r.max > 0 && r.n >= r.max {
+		r.discard(1, "buffer full")
+		return
+	}
+
+	if r.n == len(r.buf) {
+		size := 2 * len(r.buf)
+		if size == 0 {
+			size = ringSize
+		}
+		if r.max > 0 && size > r.max {
+			size = r.max
+		}
+		r.resize(size)
+	}
+	r.buf[(r.head+r.n)%len(r.buf)] = e
+	r.n++
+
+	if r.skip > 0 && r.n > r.skip {
+		// the front event may be in flight, only the ones behind it are discarded
+		for i := 1; i < r.n-1; i++ {
+			r.buf[(r.head+i)%len(r.buf)] = nil
+		}
+		r.buf[(r.head+1)%len(r.buf)] = e
+		r.discard(r.n-2, "skipped")
+		r.n = 2
+	}
+}
+
+// discard counts n dropped events
+func (r *ring) discard(n int, reason string) {
+	r.dropped += n
+	if r.drop != nil {
+		r.drop(n, reason)
+	}
+}
+
+func (r *ring) front() Event {
+	return r.buf[r.head]
+}
+
+func (r *ring) pop() {
+	r.buf[r.head] = nil
+	r.head = (r.head + 1) % len(r.buf)
+	r.n--
+
+	// refill from the disk, so the front is always in memory
+	if r.spill != nil && r.spill.count > 0 {
+		r.buf[(r.head+r.n)%len(r.buf)] = r.spill.read()
+		r.n++
+	}
+
+	// shrink after a burst, so the memory isn't held forever
+	min := ringSize
+	if r.adaptive {
+		min = r.min
+	}
+	if len(r.buf) > min && r.n <= len(r.buf)/4 {
+		size := len(r.buf) / 2
+		if size < min {
+			size = min
+		}
+		r.resize(size)
+	}
+}
+
+// resize the backing array to a new one, so the old one can be released
+func (r *ring) resize(size int) {
+	buf := make([]Event, size)
+	for i := 0; i < r.n; i++ {
+		buf[i] = r.buf[(r.head+i)%len(r.buf)]
+	}
+	r.buf = buf
+	r.head = 0
+}
+
+func (r *ring) list() []Event {
+	list := make([]Event, r.n)
+	for i := range list {
+		list[i] = r.buf[(r.head+i)%len(r.buf)]
+	}
+	if r.spill != nil {
+		list = append(list, r.spill.list()...)
+	}
+	return list
+}
+
+// len of the events in the ring, including the spilled ones
+func (r *ring) len() int {
+	if r.spill != nil {
+		return r.n + r.spill.count
+	}
+	return r.n
+}
+
+// close releases the resources of the ring, the events pushed after it are dropped
+func (r *ring) close() {
+	if r.spill != nil {
+		r.spill.close()
+	}
 }
@@ -54,3 +54,27 @@ func TestPipeCancel(t *testing.T) {
 		go p.Stop()
 	}
 }
+
+func TestPipeRing(t *testing.T) {
+	checkLeak(t)
+
+	p := goob.NewPipe()
+	defer p.Stop()
+
+	// wrap around and grow the ring while it has pending events
+	next := 0
+	for round := 0; round < 5; round++ {
+		for i := 0; i < 3+round*4; i++ {
+			p.Write(next)
+			next++
+		}
+		for i := 0; i < 3; i++ {
+			<-p.Events
+		}
+	}
+
+	expected := next - 5*3
+	for i := next - expected; i < next; i++ {
+		eq(t, i, <-p.Events)
+	}
+}
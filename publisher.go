@@ -0,0 +1,76 @@
+package goob
+
+import "sync"
+
+// Publisher is an ordered lane of an observable, see Observable.Publisher
+type Publisher struct {
+	ob *Observable
+
+	lock    *sync.Mutex
+	idle    *sync.Cond
+	queue   []Event
+	running bool
+}
+
+// Publisher returns a new lane to publish events to ob. The events of the same lane reach every subscriber in
+// the order they are published, the events of different lanes may interleave in any order. Publish of a lane only
+// waits for the lane, the lane's background goroutine publishes its queued events to ob in batches, so concurrent
+// lanes contend less on ob than concurrent calls of Observable.Publish.
+func (ob *Observable) Publisher() *Publisher {
+	lock := &sync.Mutex{}
+	return &Publisher{ob: ob, lock: lock, idle: sync.NewCond(lock)}
+}
+
+// Publish enqueues e to the lane and returns without waiting for ob, like Observable.PublishAsync
+func (p *Publisher) Publish(e Event) {
+	e = p.ob.snapshot(e)
+	if p.ob.middleware != nil {
+		p.ob.intercept(e, p.enqueue)
+		return
+	}
+	p.enqueue(e)
+}
+
+// Flush blocks until all the events published to the lane so far are published to ob
+func (p *Publisher) Flush() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	for p.running {
+		p.idle.Wait()
+	}
+}
+
+func (p *Publisher) enqueue(e Event) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.queue = append(p.queue, e)
+	if p.running {
+		return
+	}
+	p.running = true
+
+	go func() {
+		defer trackGoroutine()()
+
+		for {
+			p.lock.Lock()
+			batch := p.queue
+			p.queue = nil
+			if len(batch) == 0 {
+				p.running = false
+				p.idle.Broadcast()
+				p.lock.Unlock()
+				return
+			}
+			p.lock.Unlock()
+
+			p.ob.lock.Lock()
+			for _, e := range batch {
+				p.ob.publish(e)
+			}
+			p.ob.lock.Unlock()
+		}
+	}()
+}
@@ -0,0 +1,44 @@
+package goob_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/ysmood/goob"
+)
+
+func TestPublisher(t *testing.T) {
+	checkLeak(t)
+
+	ob := goob.New()
+	a, b := ob.Subscribe(), ob.Subscribe()
+
+	lanes := []*goob.Publisher{ob.Publisher(), ob.Publisher()}
+
+	wg := sync.WaitGroup{}
+	for i, lane := range lanes {
+		i, lane := i, lane
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				lane.Publish([2]int{i, j})
+			}
+			lane.Flush()
+		}()
+	}
+	wg.Wait()
+	ob.CloseDrain()
+
+	for _, s := range []goob.Subscriber{a, b} {
+		next := [2]int{}
+		count := 0
+		for e := range s {
+			ie := e.([2]int)
+			eq(t, next[ie[0]], ie[1])
+			next[ie[0]]++
+			count++
+		}
+		eq(t, 200, count)
+	}
+}
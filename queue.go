@@ -0,0 +1,53 @@
+package goob
+
+import "sync"
+
+// NewQueued is like New, but instead of growing the buffer of each subscriber without limit, Publish blocks
+// while any subscriber has max events that it hasn't received, so the memory of the buffered events is bounded.
+// The subscribers move in lockstep: the slowest one holds back Publish, and by that all the other subscribers,
+// which is head-of-line blocking. Close or Unsubscribe the slow subscriber to unblock Publish.
+// max < 1 is treated as 1.
+func NewQueued(max int, opts ...Option) *Observable {
+	if max < 1 {
+		max = 1
+	}
+
+	ob := New(opts...)
+	ob.queue = sync.NewCond(ob.lock)
+	ob.queueMax = max
+	return ob
+}
+
+// waitQueue blocks until the queue has room for one more event, it must be called under the lock
+func (ob *Observable) waitQueue() {
+	if ob.queue == nil {
+		return
+	}
+
+	for ob.queueFull() {
+		ob.queue.Wait()
+	}
+}
+
+func (ob *Observable) queueFull() bool {
+	for _, p := range ob.subscribers {
+		if p.len() >= ob.queueMax {
+			return true
+		}
+	}
+	return false
+}
+
+// signalQueue wakes up the blocked publishers, it must be called under the lock
+func (ob *Observable) signalQueue() {
+	if ob.queue != nil {
+		ob.queue.Broadcast()
+	}
+}
+
+// received is called by the pipe of a subscriber each time the subscriber receives an event
+func (ob *observable) received(Event, bool) {
+	ob.lock.Lock()
+	defer ob.lock.Unlock()
+	ob.queue.Broadcast()
+}
@@ -0,0 +1,72 @@
+package goob_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ysmood/goob"
+)
+
+func TestNewQueued(t *testing.T) {
+	checkLeak(t)
+
+	ob := goob.NewQueued(2)
+	defer ob.Close()
+
+	slow := ob.Subscribe()
+	fast := ob.Subscribe()
+
+	ob.Publish(1)
+	ob.Publish(2)
+	eq(t, 1, <-fast)
+	eq(t, 2, <-fast)
+
+	published := make(chan struct{})
+	go func() {
+		ob.Publish(3)
+		close(published)
+	}()
+
+	select {
+	case <-published:
+		t.Fatal("should block")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	eq(t, 1, <-slow)
+	<-published
+	eq(t, 3, <-fast)
+	eq(t, 2, <-slow)
+	eq(t, 3, <-slow)
+}
+
+func TestNewQueuedUnsubscribe(t *testing.T) {
+	checkLeak(t)
+
+	ob := goob.NewQueued(1)
+	defer ob.Close()
+
+	s := ob.Subscribe()
+	ob.Publish(1)
+
+	published := make(chan struct{})
+	go func() {
+		ob.Publish(2)
+		close(published)
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	ob.Unsubscribe(s)
+	<-published
+}
+
+func TestNewQueuedInvalidMax(t *testing.T) {
+	checkLeak(t)
+
+	ob := goob.NewQueued(0)
+	defer ob.Close()
+
+	s := ob.Subscribe()
+	ob.Publish(1)
+	eq(t, 1, <-s)
+}
@@ -0,0 +1,95 @@
+package goob
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Registry maps event types to string IDs, so that events can be serialized across processes without
+// a codec per call, see WithRegistry. It's safe for concurrent use.
+type Registry struct {
+	lock  sync.RWMutex
+	types map[string]reflect.Type
+	ids   map[reflect.Type]string
+}
+
+// NewRegistry instance
+func NewRegistry() *Registry {
+	return &Registry{
+		types: map[string]reflect.Type{},
+		ids:   map[reflect.Type]string{},
+	}
+}
+
+// Register the type of sample as id. It panics if id or the type is already registered as a different one.
+func (r *Registry) Register(id string, sample Event) {
+	t := reflect.TypeOf(sample)
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if prev, has := r.types[id]; has && prev != t {
+		panic(fmt.Sprintf("goob: type id %q is already registered for %v", id, prev))
+	}
+	if prev, has := r.ids[t]; has && prev != id {
+		panic(fmt.Sprintf("goob: type %v is already registered as %q", t, prev))
+	}
+
+	r.types[id] = t
+	r.ids[t] = id
+}
+
+// TypeID returns the id of the type of e, false if it isn't registered
+func (r *Registry) TypeID(e Event) (string, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	id, has := r.ids[reflect.TypeOf(e)]
+	return id, has
+}
+
+// registryFrame is the serialized format of Marshal
+type registryFrame struct {
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+// Marshal e as JSON along with the id of its type, the type must be registered
+func (r *Registry) Marshal(e Event) ([]byte, error) {
+	id, has := r.TypeID(e)
+	if !has {
+		return nil, fmt.Errorf("goob: type %T isn't registered", e)
+	}
+
+	value, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(registryFrame{id, value})
+}
+
+// Unmarshal the data of Marshal into an event of the registered type
+func (r *Registry) Unmarshal(data []byte) (Event, error) {
+	var frame registryFrame
+	if err := json.Unmarshal(data, &frame); err != nil {
+		return nil, err
+	}
+
+	r.lock.RLock()
+	t, has := r.types[frame.Type]
+	r.lock.RUnlock()
+
+	if !has {
+		return nil, fmt.Errorf("goob: type id %q isn't registered", frame.Type)
+	}
+
+	v := reflect.New(t)
+	if err := json.Unmarshal(frame.Value, v.Interface()); err != nil {
+		return nil, err
+	}
+
+	return v.Elem().Interface(), nil
+}
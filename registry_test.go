@@ -0,0 +1,61 @@
+package goob_test
+
+import (
+	"testing"
+
+	"github.com/ysmood/goob"
+)
+
+type userCreated struct {
+	ID   int
+	Name string
+}
+
+type orderPlaced struct {
+	ID    string
+	Total float64
+}
+
+func TestRegistry(t *testing.T) {
+	checkLeak(t)
+
+	r := goob.NewRegistry()
+	r.Register("user.created", userCreated{})
+	r.Register("order.placed", &orderPlaced{})
+
+	for _, e := range []goob.Event{userCreated{1, "jack"}, &orderPlaced{"a", 1.5}} {
+		data, err := r.Marshal(e)
+		eq(t, nil, err)
+
+		back, err := r.Unmarshal(data)
+		eq(t, nil, err)
+		eq(t, e, back)
+	}
+
+	_, err := r.Marshal(1)
+	eq(t, "goob: type int isn't registered", err.Error())
+
+	_, err = r.Unmarshal([]byte(`{"type":"none","value":1}`))
+	eq(t, `goob: type id "none" isn't registered`, err.Error())
+
+	ob := goob.New(goob.WithRegistry(r))
+	defer ob.Close()
+	s := ob.Subscribe()
+	ob.Publish(userCreated{2, "tom"})
+	ob.Publish(1)
+	eq(t, "user.created", (<-s).(goob.Envelope).TypeID)
+	eq(t, "", (<-s).(goob.Envelope).TypeID)
+}
+
+func TestRegistryConflict(t *testing.T) {
+	checkLeak(t)
+
+	r := goob.NewRegistry()
+	r.Register("user", userCreated{})
+	r.Register("user", userCreated{})
+
+	defer func() {
+		eq(t, `goob: type id "user" is already registered for goob_test.userCreated`, recover())
+	}()
+	r.Register("user", orderPlaced{})
+}
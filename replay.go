@@ -0,0 +1,112 @@
+package goob
+
+import (
+	"container/list"
+	"time"
+)
+
+// NewKeyedReplay observable instance. It retains the latest event of each key,
+// new subscribers receive them in publish order before any live event.
+func NewKeyedReplay(key func(Event) interface{}) *Observable {
+	ob := New()
+	ob.history = &keyedHistory{
+		key:   key,
+		order: list.New(),
+		index: map[interface{}]*list.Element{},
+	}
+	return ob
+}
+
+// NewFullReplay observable instance. It retains up to maxEvents published events, dropping the oldest ones
+// beyond that, and replays all of them in publish order to new subscribers before any live event.
+// The retained events are never released while ob is in use, so keep maxEvents within the memory budget.
+// It's the same as New(Replay(maxEvents)).
+func NewFullReplay(maxEvents int) *Observable {
+	return New(Replay(maxEvents))
+}
+
+// NewReplayWithin observable instance. It retains the events published within the last d, measured by the clock of
+// the observable, and replays them in publish order to new subscribers before any live event.
+func NewReplayWithin(d time.Duration, opts ...Option) *Observable {
+	ob := New(opts...)
+	ob.history = &timeHistory{d: d, clock: ob.clock}
+	return ob
+}
+
+type keyedHistory struct {
+	key   func(Event) interface{}
+	order *list.List
+	index map[interface{}]*list.Element
+}
+
+func (h *keyedHistory) add(e Event) {
+	k := h.key(e)
+	if el, has := h.index[k]; has {
+		h.order.Remove(el)
+	}
+	h.index[k] = h.order.PushBack(e)
+}
+
+func (h *keyedHistory) events() []Event {
+	es := make([]Event, 0, h.order.Len())
+	for el := h.order.Front(); el != nil; el = el.Next() {
+		es = append(es, el.Value)
+	}
+	return es
+}
+
+// fullHistory retains all the events, or the last max events if max > 0
+type fullHistory struct {
+	max  int
+	list []Event
+}
+
+func (h *fullHistory) add(e Event) {
+	if h.max > 0 && len(h.list) == h.max {
+		h.list[0] = nil
+		h.list = h.list[1:]
+	}
+	h.list = append(h.list, e)
+}
+
+func (h *fullHistory) events() []Event {
+	return h.list
+}
+
+// timeHistory retains the events published within the last d
+type timeHistory struct {
+	d     time.Duration
+	clock Clock
+	list  []timedEvent
+}
+
+type timedEvent struct {
+	e  Event
+	at time.Time
+}
+
+func (h *timeHistory) add(e Event) {
+	now := h.clock.Now()
+	h.expire(now)
+	h.list = append(h.list, timedEvent{e, now})
+}
+
+func (h *timeHistory) events() []Event {
+	h.expire(h.clock.Now())
+
+	es := make([]Event, len(h.list))
+	for i, t := range h.list {
+		es[i] = t.e
+	}
+	return es
+}
+
+// expire drops the events older than d
+func (h *timeHistory) expire(now time.Time) {
+	i := 0
+	for i < len(h.list) && now.Sub(h.list[i].at) >= h.d {
+		h.list[i] = timedEvent{}
+		i++
+	}
+	h.list = h.list[i:]
+}
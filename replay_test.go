@@ -0,0 +1,123 @@
+package goob_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ysmood/goob"
+)
+
+type kv struct {
+	k string
+	v int
+}
+
+func TestKeyedReplay(t *testing.T) {
+	checkLeak(t)
+
+	ob := goob.NewKeyedReplay(func(e goob.Event) interface{} {
+		return e.(kv).k
+	})
+	defer ob.Close()
+
+	ob.Publish(kv{"a", 1})
+	ob.Publish(kv{"b", 1})
+	ob.Publish(kv{"a", 2})
+
+	s := ob.Subscribe()
+	ob.Publish(kv{"b", 2})
+
+	eq(t, kv{"b", 1}, <-s)
+	eq(t, kv{"a", 2}, <-s)
+	eq(t, kv{"b", 2}, <-s)
+}
+
+func TestKeyedReplayConcurrent(t *testing.T) {
+	checkLeak(t)
+
+	const size = 1000
+	const keys = 3
+
+	ob := goob.NewKeyedReplay(func(e goob.Event) interface{} {
+		if e == nil {
+			return nil
+		}
+		return e.(kv).k
+	})
+	defer ob.Close()
+
+	go func() {
+		for i := 0; i < size; i++ {
+			ob.Publish(kv{string(rune('a' + i%keys)), i})
+		}
+		ob.Publish(nil)
+	}()
+
+	wg := sync.WaitGroup{}
+	wg.Add(10)
+	for i := 0; i < 10; i++ {
+		go func() {
+			defer wg.Done()
+
+			latest := map[string]int{}
+			for e := range ob.Subscribe() {
+				if e == nil {
+					return
+				}
+				v := e.(kv)
+				if last, has := latest[v.k]; has && last >= v.v {
+					t.Error("out of order", v, last)
+				}
+				latest[v.k] = v.v
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestFullReplay(t *testing.T) {
+	checkLeak(t)
+
+	ob := goob.NewFullReplay(100)
+
+	expected := []goob.Event{}
+	for i := 0; i < 50; i++ {
+		ob.Publish(i)
+		expected = append(expected, i)
+	}
+	ob.CloseDrain()
+
+	eq(t, expected, collect(ob.Subscribe()))
+
+	ob = goob.NewFullReplay(3)
+	for i := 0; i < 5; i++ {
+		ob.Publish(i)
+	}
+	ob.CloseDrain()
+
+	eq(t, []goob.Event{2, 3, 4}, collect(ob.Subscribe()))
+}
+
+func TestNewReplayWithin(t *testing.T) {
+	checkLeak(t)
+
+	clock := goob.NewTestClock()
+	ob := goob.NewReplayWithin(10*time.Millisecond, goob.WithClock(clock))
+	defer ob.Close()
+
+	ob.Publish(1)
+	clock.Advance(6 * time.Millisecond)
+	ob.Publish(2)
+	clock.Advance(6 * time.Millisecond)
+
+	s := ob.Subscribe()
+	ob.Publish(3)
+	eq(t, 2, <-s)
+	eq(t, 3, <-s)
+
+	clock.Advance(time.Hour)
+	s = ob.Subscribe()
+	ob.Publish(4)
+	eq(t, 4, <-s)
+}
@@ -0,0 +1,119 @@
+package goob
+
+import (
+	"context"
+	"time"
+)
+
+// FromSlice creates a completed observable, each subscriber receives all the events before its channel is closed
+func FromSlice(events []Event) *Observable {
+	ob := New()
+	ob.history = &fullHistory{}
+
+	for _, e := range events {
+		ob.Publish(e)
+	}
+
+	ob.CloseDrain()
+
+	return ob
+}
+
+// Backoff returns how long to wait before reconnecting, failures is the number of consecutive failed connects
+type Backoff func(failures int) time.Duration
+
+// FromChanFunc calls connect to get a channel and publishes its events, once the channel is closed it reconnects
+// after the backoff. The error returned by connect is published as an event.
+// The returned observable is closed when ctx is done.
+func FromChanFunc(ctx context.Context, connect func(context.Context) (<-chan Event, error), backoff Backoff) *Observable {
+	return lazy(func(ob *Observable) {
+		go func() {
+			defer trackGoroutine()()
+			defer ob.Close()
+
+			failures := 0
+			for {
+				c, err := connect(ctx)
+				if err != nil {
+					failures++
+					ob.Publish(err)
+				} else {
+					failures = 0
+					if !forward(ctx, c, ob) {
+						return
+					}
+				}
+
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(backoff(failures)):
+				}
+			}
+		}()
+	})
+}
+
+// forward the events of c to ob until c is closed, returns false if ctx is done
+func forward(ctx context.Context, c <-chan Event, ob *Observable) bool {
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case e, ok := <-c:
+			if !ok {
+				return true
+			}
+			ob.Publish(e)
+		}
+	}
+}
+
+// FromCallback bridges a callback-based API: it calls register with an emit function that publishes the event,
+// emit is safe to call from any goroutine. When ctx is done the cancel function that register returns is called
+// and the returned observable is closed. register is called once the observable is subscribed.
+func FromCallback(ctx context.Context, register func(emit func(Event)) (cancel func())) *Observable {
+	return lazy(func(ob *Observable) {
+		go func() {
+			defer trackGoroutine()()
+			cancel := register(ob.Publish)
+			<-ctx.Done()
+			cancel()
+			ob.Close()
+		}()
+	})
+}
+
+// Backfill subscribes live, then emits the events that query returns, then the events of live, including the ones
+// published while query runs, so no live event between the two is lost. If query fails its error is emitted as
+// an event and the returned observable completes. query is called once the returned observable is subscribed.
+// The returned observable completes once live completes, or is closed once ctx is done.
+func Backfill(ctx context.Context, query func(context.Context) ([]Event, error), live *Observable) *Observable {
+	return live.derive(func(out *Observable) {
+		s := live.follow()
+		guard := supervise(ctx)
+
+		go func() {
+			defer trackGoroutine()()
+			defer guard(out)
+			defer live.Unsubscribe(s)
+
+			list, err := query(ctx)
+			if err != nil {
+				out.Publish(err)
+				out.CloseDrain()
+				return
+			}
+
+			for _, e := range list {
+				out.Publish(e)
+			}
+
+			if forward(ctx, s, out) {
+				out.CloseDrain()
+			} else {
+				out.Close()
+			}
+		}()
+	})
+}
@@ -0,0 +1,122 @@
+package goob_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ysmood/goob"
+)
+
+func TestFromSlice(t *testing.T) {
+	checkLeak(t)
+
+	ob := goob.FromSlice([]goob.Event{1, 2, 3})
+
+	eq(t, []goob.Event{1, 2, 3}, collect(ob.Subscribe()))
+	eq(t, []goob.Event{1, 2, 3}, collect(ob.Subscribe()))
+}
+
+func TestFromChanFunc(t *testing.T) {
+	checkLeak(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	errConnect := errors.New("connect")
+	reconnected := make(chan struct{})
+	connects := 0
+	connect := func(ctx context.Context) (<-chan goob.Event, error) {
+		connects++
+		switch connects {
+		case 1:
+			return nil, errConnect
+		case 2:
+			c := make(chan goob.Event, 2)
+			c <- 1
+			c <- 2
+			close(c)
+			return c, nil
+		default:
+			close(reconnected)
+			return make(chan goob.Event), nil
+		}
+	}
+
+	backoffs := []int{}
+	backoff := func(failures int) time.Duration {
+		backoffs = append(backoffs, failures)
+		return time.Millisecond
+	}
+
+	s := goob.FromChanFunc(ctx, connect, backoff).Subscribe()
+
+	eq(t, errConnect, <-s)
+	eq(t, 1, <-s)
+	eq(t, 2, <-s)
+
+	<-reconnected
+	cancel()
+	collect(s)
+
+	eq(t, 3, connects)
+	eq(t, []int{1, 0}, backoffs)
+}
+
+func TestFromCallback(t *testing.T) {
+	checkLeak(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	unregistered := make(chan struct{})
+	ob := goob.FromCallback(ctx, func(emit func(goob.Event)) func() {
+		go func() {
+			for i := 0; i < 3; i++ {
+				emit(i)
+			}
+		}()
+		return func() { close(unregistered) }
+	})
+
+	s := ob.Subscribe()
+	eq(t, 0, <-s)
+	eq(t, 1, <-s)
+	eq(t, 2, <-s)
+
+	cancel()
+	<-unregistered
+	collect(s)
+}
+
+func TestBackfill(t *testing.T) {
+	checkLeak(t)
+
+	live := goob.New()
+	querying := make(chan struct{})
+	resume := make(chan struct{})
+
+	s := goob.Backfill(context.Background(), func(context.Context) ([]goob.Event, error) {
+		close(querying)
+		<-resume
+		return []goob.Event{"row1", "row2"}, nil
+	}, live).Subscribe()
+
+	<-querying
+	live.Publish("change1")
+	live.Publish("change2")
+	close(resume)
+	live.Publish("change3")
+	live.CloseDrain()
+
+	eq(t, []goob.Event{"row1", "row2", "change1", "change2", "change3"}, collect(s))
+
+	errQuery := errors.New("query")
+	failed := goob.New()
+	defer failed.Close()
+	s = goob.Backfill(context.Background(), func(context.Context) ([]goob.Event, error) {
+		return nil, errQuery
+	}, failed).Subscribe()
+
+	eq(t, []goob.Event{errQuery}, collect(s))
+}
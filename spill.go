@@ -0,0 +1,147 @@
+package goob
+
+import (
+	"context"
+	"encoding/binary"
+	"os"
+	"time"
+)
+
+// Codec encodes events to bytes and decodes them back, see SubscribeSpill
+type Codec interface {
+	Encode(Event) ([]byte, error)
+	Decode([]byte) (Event, error)
+}
+
+// SubscribeSpill is like Subscribe, but once memCap events are waiting for the subscriber in memory, the following
+// ones are encoded by codec into a temporary file in dir, and read back in order as the subscriber catches up.
+// The events that fail to encode are dropped, see SubscriberInfo.Dropped. An event that fails to decode is
+// received as the error instead. The file is removed once the subscriber's channel is closed.
+// The deadlines of PublishWithDeadline are kept along with the spilled events.
+// It unsubscribes when ctx is done.
+func (ob *Observable) SubscribeSpill(ctx context.Context, memCap int, dir string, codec Codec) Subscriber {
+	if memCap < 1 {
+		memCap = 1
+	}
+	return ob.subscribe(ctx, &subscriber{buffer: &ring{spill: &spill{memCap: memCap, dir: dir, codec: codec}}})
+}
+
+// spill is a FIFO of encoded events in a file, each record is the varint deadline in Unix nanoseconds, 0 means
+// none, and a uvarint length followed by the encoded event
+type spill struct {
+	memCap int
+	dir    string
+	codec  Codec
+
+	// clock of the deadlines, see PublishWithDeadline
+	clock Clock
+
+	file   *os.File
+	closed bool
+
+	// count of the events in the file, and the offsets of the next read and write
+	count  int
+	rd, wr int64
+}
+
+// write e to the end of the file, it returns false if e failed to be encoded or written
+func (s *spill) write(e Event) bool {
+	if s.closed {
+		return false
+	}
+
+	var deadline int64
+	if d, ok := e.(deadlined); ok {
+		e, deadline, s.clock = d.e, d.at.UnixNano(), d.clock
+	}
+
+	data, err := s.codec.Encode(e)
+	if err != nil {
+		return false
+	}
+
+	if s.file == nil {
+		s.file, err = os.CreateTemp(s.dir, "goob-spill-*")
+		if err != nil {
+			return false
+		}
+	}
+
+	record := make([]byte, 2*binary.MaxVarintLen64+len(data))
+	l := binary.PutVarint(record, deadline)
+	l += binary.PutUvarint(record[l:], uint64(len(data)))
+	record = append(record[:l], data...)
+	if _, err := s.file.WriteAt(record, s.wr); err != nil {
+		return false
+	}
+
+	s.wr += int64(len(record))
+	s.count++
+	return true
+}
+
+// read the first event of the file, it must only be called when count > 0
+func (s *spill) read() Event {
+	e, next, err := s.decode(s.rd)
+	s.count--
+	s.rd = next
+
+	// the file is empty, reuse it from the beginning
+	if s.count == 0 {
+		s.rd, s.wr = 0, 0
+		_ = s.file.Truncate(0)
+	}
+
+	if err != nil {
+		return err
+	}
+	return e
+}
+
+func (s *spill) decode(offset int64) (Event, int64, error) {
+	head := make([]byte, 2*binary.MaxVarintLen64)
+	n, _ := s.file.ReadAt(head, offset)
+
+	deadline, l := binary.Varint(head[:n])
+	if l <= 0 {
+		return nil, s.wr, os.ErrInvalid
+	}
+	size, ls := binary.Uvarint(head[l:n])
+	if ls <= 0 {
+		return nil, s.wr, os.ErrInvalid
+	}
+	l += ls
+
+	data := make([]byte, size)
+	if _, err := s.file.ReadAt(data, offset+int64(l)); err != nil {
+		return nil, s.wr, err
+	}
+
+	e, err := s.codec.Decode(data)
+	if err == nil && deadline != 0 {
+		e = deadlined{e, time.Unix(0, deadline), s.clock}
+	}
+	return e, offset + int64(l) + int64(size), err
+}
+
+func (s *spill) list() []Event {
+	list := make([]Event, 0, s.count)
+	offset := s.rd
+	for i := 0; i < s.count; i++ {
+		e, next, err := s.decode(offset)
+		if err != nil {
+			e = err
+		}
+		list = append(list, e)
+		offset = next
+	}
+	return list
+}
+
+func (s *spill) close() {
+	s.closed = true
+	if s.file != nil {
+		_ = s.file.Close()
+		_ = os.Remove(s.file.Name())
+	}
+}
@@ -0,0 +1,79 @@
+package goob_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ysmood/goob"
+)
+
+type jsonCodec struct{}
+
+func (jsonCodec) Encode(e goob.Event) ([]byte, error) {
+	return json.Marshal(e)
+}
+
+func (jsonCodec) Decode(data []byte) (goob.Event, error) {
+	var n int
+	err := json.Unmarshal(data, &n)
+	return n, err
+}
+
+func TestSubscribeSpill(t *testing.T) {
+	checkLeak(t)
+
+	dir := t.TempDir()
+
+	ob := goob.New()
+	defer ob.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := ob.SubscribeSpill(ctx, 10, dir, jsonCodec{})
+
+	for i := 0; i < 1000; i++ {
+		ob.Publish(i)
+	}
+
+	files, _ := os.ReadDir(dir)
+	eq(t, 1, len(files))
+	eq(t, 1000, len(ob.Pending(s)))
+	eq(t, 999, ob.Pending(s)[999])
+
+	for i := 0; i < 1000; i++ {
+		eq(t, i, <-s)
+	}
+
+	ob.Publish(1000)
+	eq(t, 1000, <-s)
+
+	cancel()
+	collect(s)
+	settle()
+
+	files, _ = os.ReadDir(dir)
+	eq(t, 0, len(files))
+}
+
+func TestSubscribeSpillDeadline(t *testing.T) {
+	checkLeak(t)
+
+	clock := goob.NewTestClock()
+	ob := goob.New(goob.WithClock(clock))
+	defer ob.Close()
+
+	s := ob.SubscribeSpill(context.Background(), 1, t.TempDir(), jsonCodec{})
+	for i := 0; i < 3; i++ {
+		ob.PublishWithDeadline(i, 10*time.Millisecond)
+	}
+	ob.Publish(3)
+
+	eq(t, []goob.Event{0, 1, 2, 3}, ob.Pending(s))
+	eq(t, 0, <-s)
+
+	clock.Advance(10 * time.Millisecond)
+	eq(t, 3, <-s)
+	eq(t, 2, ob.Subscribers()[0].Dropped)
+}
@@ -0,0 +1,67 @@
+package goob
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// SSE returns a handler that streams the events of ob as server-sent events, each event is encoded as the data field.
+// For an Envelope its Value is encoded and its Seq is sent as the id field, so when a client reconnects with the
+// Last-Event-ID header the retained events after it are replayed, see SubscribeFrom. An id beyond the current Seq,
+// such as one from before the server restarted, replays all the retained events.
+// The subscriber is named after the remote address of the request, see Subscribers.
+// The stream ends when ob is closed, the request is done, or encode fails.
+func SSE(ob *Observable, encode func(Event) ([]byte, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+			return
+		}
+
+		p := &subscriber{name: r.RemoteAddr}
+		if id := r.Header.Get("Last-Event-ID"); id != "" {
+			seq, err := strconv.ParseUint(id, 10, 64)
+			if err != nil {
+				http.Error(w, "invalid Last-Event-ID", http.StatusBadRequest)
+				return
+			}
+			if seq > ob.currentSeq() {
+				seq = 0
+			}
+			p.filter = after(seq)
+		}
+		s := ob.subscribe(r.Context(), p)
+		defer ob.Unsubscribe(s)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		for e := range s {
+			buf := bytes.NewBuffer(nil)
+
+			if env, ok := e.(Envelope); ok {
+				fmt.Fprintf(buf, "id: %d\n", env.Seq)
+				e = env.Value
+			}
+
+			data, err := encode(e)
+			if err != nil {
+				return
+			}
+			for _, line := range bytes.Split(data, []byte("\n")) {
+				fmt.Fprintf(buf, "data: %s\n", line)
+			}
+			buf.WriteString("\n")
+
+			if _, err := w.Write(buf.Bytes()); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	})
+}
@@ -0,0 +1,110 @@
+package goob_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ysmood/goob"
+)
+
+func encodeJSON(e goob.Event) ([]byte, error) {
+	return json.Marshal(e)
+}
+
+func TestSSE(t *testing.T) {
+	checkLeak(t)
+
+	ob := goob.New(goob.WithEnvelope(), goob.Replay(10))
+	defer ob.Close()
+
+	srv := httptest.NewServer(goob.SSE(ob, encodeJSON))
+	defer srv.Close()
+
+	ob.Publish(1)
+	ob.Publish(2)
+	ob.Publish(3)
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	req.Header.Set("Last-Event-ID", "1")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	eq(t, "text/event-stream", res.Header.Get("Content-Type"))
+
+	lines := bufio.NewScanner(res.Body)
+	next := func() string {
+		lines.Scan()
+		return lines.Text()
+	}
+
+	eq(t, "id: 2", next())
+	eq(t, "data: 2", next())
+	eq(t, "", next())
+	eq(t, "id: 3", next())
+	eq(t, "data: 3", next())
+	eq(t, "", next())
+
+	ob.Publish("live")
+	eq(t, "id: 4", next())
+	eq(t, `data: "live"`, next())
+}
+
+func TestSSEInvalidLastEventID(t *testing.T) {
+	checkLeak(t)
+
+	ob := goob.New()
+	defer ob.Close()
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Last-Event-ID", "x")
+	res := httptest.NewRecorder()
+
+	goob.SSE(ob, encodeJSON).ServeHTTP(res, req)
+
+	eq(t, http.StatusBadRequest, res.Code)
+}
+
+func TestSSEReconnectAfterReset(t *testing.T) {
+	checkLeak(t)
+
+	// a fresh observable, as after a server restart
+	ob := goob.New(goob.WithEnvelope(), goob.Replay(10))
+	defer ob.Close()
+
+	srv := httptest.NewServer(goob.SSE(ob, encodeJSON))
+	defer srv.Close()
+
+	ob.Publish(1)
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	req.Header.Set("Last-Event-ID", "500")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	lines := bufio.NewScanner(res.Body)
+	next := func() string {
+		lines.Scan()
+		return lines.Text()
+	}
+
+	eq(t, "id: 1", next())
+	eq(t, "data: 1", next())
+	eq(t, "", next())
+
+	eq(t, 1, len(ob.Subscribers()))
+	eq(t, true, strings.HasPrefix(ob.Subscribers()[0].Name, "127.0.0.1:"))
+
+	ob.Publish(2)
+	eq(t, "id: 2", next())
+	eq(t, "data: 2", next())
+}
@@ -0,0 +1,82 @@
+package goob
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrStuck is the reason of OnUnsubscribe for a subscriber that StuckTimeout evicts
+var ErrStuck = errors.New("goob: the subscriber stopped receiving events")
+
+// StuckTimeout evicts a subscriber that has pending events but hasn't received any of them for d, which protects
+// the memory from a consumer that stops reading forever. The eviction closes the subscriber's channel, and calls
+// the OnUnsubscribe callback with ErrStuck. The check runs every d/2 on the clock of the observable while it has
+// subscribers, so a stuck subscriber is evicted within 1.5 * d.
+func StuckTimeout(d time.Duration) Option {
+	return func(ob *observable) {
+		ob.stuckTimeout = d
+	}
+}
+
+// watchStuck starts the watchdog of StuckTimeout if it's not running, it must be called under the lock
+func (ob *Observable) watchStuck() {
+	if ob.stuckTimeout <= 0 || ob.watching {
+		return
+	}
+	ob.watching = true
+	stop := make(chan struct{})
+	ob.stopWatching = func() { close(stop) }
+
+	// the watchdog only holds a copy of ob, see Debug
+	internal := &Observable{ob.observable}
+	ticker := ob.clock.NewTicker(ob.stuckTimeout / 2)
+
+	go func() {
+		defer trackGoroutine()()
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.Chan():
+				if !internal.evictStuck() {
+					return
+				}
+			}
+		}
+	}()
+}
+
+// unwatchStuck stops the watchdog if it's running, it must be called under the lock
+func (ob *Observable) unwatchStuck() {
+	if ob.watching {
+		ob.watching = false
+		ob.stopWatching()
+	}
+}
+
+// evictStuck returns false and stops watching when there's no subscriber any more
+func (ob *Observable) evictStuck() bool {
+	ob.lock.Lock()
+	defer ob.lock.Unlock()
+
+	now := ob.clock.Now()
+	for s, p := range ob.subscribers {
+		if p.len() > 0 && now.Sub(p.offered()) >= ob.stuckTimeout {
+			p.reason = ErrStuck
+			if ob.logger != nil {
+				ob.logger.Warn("goob: subscriber evicted", "subscriber", p.id, "name", p.name, "reason", ErrStuck)
+			}
+			p.Stop()
+			delete(ob.subscribers, s)
+			ob.signalQueue()
+		}
+	}
+
+	if len(ob.subscribers) == 0 {
+		ob.unwatchStuck()
+		return false
+	}
+	return true
+}
@@ -0,0 +1,45 @@
+package goob_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ysmood/goob"
+)
+
+func TestStuckTimeout(t *testing.T) {
+	checkLeak(t)
+
+	clock := goob.NewTestClock()
+	reasons := make(chan error, 2)
+
+	ob := goob.New(
+		goob.WithClock(clock),
+		goob.StuckTimeout(time.Second),
+		goob.OnUnsubscribe(func(_ uint64, reason error) { reasons <- reason }),
+	)
+	defer ob.Close()
+
+	reader := ob.Subscribe()
+	stuck := ob.Subscribe()
+	clock.BlockUntil(1)
+
+	ob.Publish(1)
+	eq(t, 1, <-stuck) // it reads once then stops
+	eq(t, 1, <-reader)
+	ob.Publish(2)
+	eq(t, 2, <-reader)
+	settle()
+
+	clock.Advance(500 * time.Millisecond)
+	settle()
+	eq(t, 2, ob.Len())
+
+	clock.Advance(500 * time.Millisecond)
+	eq(t, goob.ErrStuck, <-reasons)
+	eq(t, []goob.Event{}, collect(stuck))
+	eq(t, 1, ob.Len())
+
+	ob.Unsubscribe(reader)
+	eq(t, nil, <-reasons)
+}
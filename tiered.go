@@ -0,0 +1,76 @@
+package goob
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// SubscribeTiered is like Subscribe, but the events are buffered in tiers, tier returns the tier index of an event,
+// 0 is the highest, and an index out of range is the lowest tier. The events of a higher tier are always received
+// before the ones of lower tiers, events of the same tier keep their order. Tier i holds at most tierSizes[i]
+// events, the following events of a full tier are dropped, so a flood of one tier never evicts another,
+// they are counted by SubscriberInfo.Dropped and logged by WithLogger.
+// A size <= 0 means no limit, no tierSizes means a single tier without limit.
+// The returned channel is closed once ob completes or ctx is done.
+func (ob *Observable) SubscribeTiered(ctx context.Context, tierSizes []int, tier func(Event) int) <-chan Event {
+	if len(tierSizes) == 0 {
+		tierSizes = []int{0}
+	}
+
+	p := &subscriber{}
+	s := ob.subscribe(ctx, p)
+	out := make(chan Event)
+
+	go func() {
+		defer trackGoroutine()()
+		defer close(out)
+
+		queues := make([][]Event, len(tierSizes))
+		in := s
+
+		for {
+			var send chan<- Event
+			var front Event
+			top := -1
+			for i, q := range queues {
+				if len(q) > 0 {
+					send, front, top = out, q[0], i
+					break
+				}
+			}
+
+			if in == nil && top == -1 {
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+
+			case e, ok := <-in:
+				if !ok {
+					in = nil
+					continue
+				}
+				i := tier(e)
+				if i < 0 || i >= len(queues) {
+					i = len(queues) - 1
+				}
+				if tierSizes[i] <= 0 || len(queues[i]) < tierSizes[i] {
+					queues[i] = append(queues[i], e)
+				} else {
+					atomic.AddInt64(&p.discarded, 1)
+					if ob.logger != nil {
+						ob.logDrop(p, 1, "tier full")
+					}
+				}
+
+			case send <- front:
+				queues[top][0] = nil
+				queues[top] = queues[top][1:]
+			}
+		}
+	}()
+
+	return out
+}
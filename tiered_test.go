@@ -0,0 +1,44 @@
+package goob_test
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/ysmood/goob"
+)
+
+func TestSubscribeTiered(t *testing.T) {
+	checkLeak(t)
+
+	type job struct {
+		high bool
+		id   int
+	}
+	tier := func(e goob.Event) int {
+		if e.(job).high {
+			return 0
+		}
+		return 1
+	}
+
+	out := &syncBuffer{}
+	ob := goob.New(goob.WithLogger(slog.New(slog.NewTextHandler(out, nil))))
+	s := ob.SubscribeTiered(context.Background(), []int{10, 3}, tier)
+
+	for i := 0; i < 5; i++ {
+		ob.Publish(job{false, i})
+	}
+	ob.Publish(job{true, 0})
+	ob.Publish(job{true, 1})
+	settle()
+	eq(t, 2, ob.Subscribers()[0].Dropped)
+	eq(t, 2, strings.Count(out.String(), "reason=\"tier full\""))
+	ob.CloseDrain()
+
+	eq(t, []goob.Event{
+		job{true, 0}, job{true, 1},
+		job{false, 0}, job{false, 1}, job{false, 2},
+	}, collect(s))
+}
@@ -0,0 +1,545 @@
+package goob
+
+import (
+	"container/heap"
+	"context"
+	"math"
+	"sort"
+	"time"
+)
+
+// ThrottleTime emits at most one event of ob per d. If leading is true the event that opens a window is emitted
+// immediately. If trailing is true the latest event received during a window is emitted when the window ends,
+// which opens the next window. An event is never emitted twice.
+func (ob *Observable) ThrottleTime(ctx context.Context, d time.Duration, leading, trailing bool) *Observable {
+	return ob.derive(func(out *Observable) {
+		s := ob.follow()
+
+		go func() {
+			defer trackGoroutine()()
+			defer ob.Unsubscribe(s)
+
+			var window <-chan time.Time
+			var latest Event
+			has := false
+
+			for {
+				select {
+				case <-ctx.Done():
+					out.Close()
+					return
+
+				case e, ok := <-s:
+					if !ok {
+						if has {
+							out.Publish(latest)
+						}
+						out.CloseDrain()
+						return
+					}
+
+					if window == nil {
+						window = ob.clock.After(d)
+						if leading {
+							out.Publish(e)
+							continue
+						}
+					}
+					if trailing {
+						latest, has = e, true
+					}
+
+				case <-window:
+					window = nil
+					if has {
+						out.Publish(latest)
+						latest, has = nil, false
+						window = ob.clock.After(d)
+					}
+				}
+			}
+		}()
+	})
+}
+
+// ThrottleLatest emits the first event of ob immediately, then at most one event per d, which is the latest event
+// received during the window. The window opens on event arrival rather than on a fixed schedule.
+// It's the same as ThrottleTime with both edges.
+func (ob *Observable) ThrottleLatest(ctx context.Context, d time.Duration) *Observable {
+	return ob.ThrottleTime(ctx, d, true, true)
+}
+
+// Debounce emits the latest event of ob once d passes without any new event.
+// When ob completes the pending event is emitted.
+func (ob *Observable) Debounce(ctx context.Context, d time.Duration) *Observable {
+	return ob.derive(func(out *Observable) {
+		s := ob.follow()
+
+		go func() {
+			defer trackGoroutine()()
+			defer ob.Unsubscribe(s)
+
+			var timer <-chan time.Time
+			var latest Event
+
+			for {
+				select {
+				case <-ctx.Done():
+					out.Close()
+					return
+
+				case e, ok := <-s:
+					if !ok {
+						if timer != nil {
+							out.Publish(latest)
+						}
+						out.CloseDrain()
+						return
+					}
+					latest = e
+					timer = ob.clock.After(d)
+
+				case <-timer:
+					timer = nil
+					out.Publish(latest)
+					latest = nil
+				}
+			}
+		}()
+	})
+}
+
+// DebounceDistinct is like Debounce, but the settled event is only emitted if eq reports it differs from the last
+// emitted one, so a burst that settles back on the same value emits nothing. If eq is nil the default of Equality
+// is used.
+func (ob *Observable) DebounceDistinct(ctx context.Context, d time.Duration, eq func(a, b Event) bool) *Observable {
+	eq = ob.equality(eq)
+
+	return ob.derive(func(out *Observable) {
+		s := ob.follow()
+		guard := supervise(ctx)
+
+		go func() {
+			defer trackGoroutine()()
+			defer guard(out)
+			defer ob.Unsubscribe(s)
+
+			var timer <-chan time.Time
+			var latest, last Event
+			emitted := false
+
+			emit := func() {
+				if !emitted || !eq(last, latest) {
+					out.Publish(latest)
+					last, emitted = latest, true
+				}
+				latest = nil
+			}
+
+			for {
+				select {
+				case <-ctx.Done():
+					out.Close()
+					return
+
+				case e, ok := <-s:
+					if !ok {
+						if timer != nil {
+							emit()
+						}
+						out.CloseDrain()
+						return
+					}
+					latest = e
+					timer = ob.clock.After(d)
+
+				case <-timer:
+					timer = nil
+					emit()
+				}
+			}
+		}()
+	})
+}
+
+// DebounceAdaptive is like Debounce, but the delay adapts to the interval between the last two events of ob:
+// delay = max - (max - min) * interval / max, so it's min for the first event and for intervals of at least max,
+// and grows linearly toward max as the events arrive faster.
+func (ob *Observable) DebounceAdaptive(ctx context.Context, min, max time.Duration) *Observable {
+	return ob.derive(func(out *Observable) {
+		s := ob.follow()
+
+		go func() {
+			defer trackGoroutine()()
+			defer ob.Unsubscribe(s)
+
+			var timer <-chan time.Time
+			var latest Event
+			var last time.Time
+
+			for {
+				select {
+				case <-ctx.Done():
+					out.Close()
+					return
+
+				case e, ok := <-s:
+					if !ok {
+						if timer != nil {
+							out.Publish(latest)
+						}
+						out.CloseDrain()
+						return
+					}
+
+					now := ob.clock.Now()
+					delay := min
+					if !last.IsZero() {
+						if interval := now.Sub(last); interval < max {
+							delay = max - time.Duration(float64(max-min)*float64(interval)/float64(max))
+						}
+					}
+					last = now
+
+					latest = e
+					timer = ob.clock.After(delay)
+
+				case <-timer:
+					timer = nil
+					out.Publish(latest)
+					latest = nil
+				}
+			}
+		}()
+	})
+}
+
+// Rate emits, every window, the number of events per second that ob emitted during the window, as a float64.
+// The returned observable completes once ob completes. A window <= 0 is treated as 1 second.
+func (ob *Observable) Rate(ctx context.Context, window time.Duration) *Observable {
+	if window <= 0 {
+		window = time.Second
+	}
+
+	return ob.derive(func(out *Observable) {
+		s := ob.follow()
+		ticker := ob.clock.NewTicker(window)
+
+		go func() {
+			defer trackGoroutine()()
+			defer ob.Unsubscribe(s)
+			defer ticker.Stop()
+
+			count := 0
+			for {
+				select {
+				case <-ctx.Done():
+					out.Close()
+					return
+
+				case _, ok := <-s:
+					if !ok {
+						out.CloseDrain()
+						return
+					}
+					count++
+
+				case <-ticker.Chan():
+					out.Publish(float64(count) / window.Seconds())
+					count = 0
+				}
+			}
+		}()
+	})
+}
+
+// CoalesceSignal emits a struct{}{} at the end of each d window opened by an event of ob, no matter how many events
+// arrive during the window, so a consumer only learns that something changed since the last signal.
+// Unlike Debounce a steady stream can't postpone the signal. When ob completes the pending signal is emitted.
+func (ob *Observable) CoalesceSignal(ctx context.Context, d time.Duration) *Observable {
+	return ob.derive(func(out *Observable) {
+		s := ob.follow()
+
+		go func() {
+			defer trackGoroutine()()
+			defer ob.Unsubscribe(s)
+
+			var window <-chan time.Time
+
+			for {
+				select {
+				case <-ctx.Done():
+					out.Close()
+					return
+
+				case _, ok := <-s:
+					if !ok {
+						if window != nil {
+							out.Publish(struct{}{})
+						}
+						out.CloseDrain()
+						return
+					}
+					if window == nil {
+						window = ob.clock.After(d)
+					}
+
+				case <-window:
+					window = nil
+					out.Publish(struct{}{})
+				}
+			}
+		}()
+	})
+}
+
+// Heartbeat emits the events of ob, and emits beat each time d passes without any event emitted,
+// so an idle stream still shows it's alive. The returned observable completes once ob completes.
+func (ob *Observable) Heartbeat(ctx context.Context, d time.Duration, beat Event) *Observable {
+	return ob.derive(func(out *Observable) {
+		s := ob.follow()
+
+		go func() {
+			defer trackGoroutine()()
+			defer ob.Unsubscribe(s)
+
+			timer := ob.clock.After(d)
+
+			for {
+				select {
+				case <-ctx.Done():
+					out.Close()
+					return
+
+				case e, ok := <-s:
+					if !ok {
+						out.CloseDrain()
+						return
+					}
+					out.Publish(e)
+					timer = ob.clock.After(d)
+
+				case <-timer:
+					out.Publish(beat)
+					timer = ob.clock.After(d)
+				}
+			}
+		}()
+	})
+}
+
+// Jitter emits, for each event of ob after the first one, the standard deviation of the intervals between the last
+// window+1 events of ob as a time.Duration, measured by the clock of ob, so a regular producer gets values near 0.
+// A window below 2 is treated as 2. The returned observable completes once ob completes.
+func (ob *Observable) Jitter(ctx context.Context, window int) *Observable {
+	if window < 2 {
+		window = 2
+	}
+
+	return ob.derive(func(out *Observable) {
+		s := ob.follow()
+
+		go func() {
+			defer trackGoroutine()()
+			defer ob.Unsubscribe(s)
+
+			var last time.Time
+			intervals := []float64{}
+
+			for {
+				select {
+				case <-ctx.Done():
+					out.Close()
+					return
+
+				case _, ok := <-s:
+					if !ok {
+						out.CloseDrain()
+						return
+					}
+
+					now := ob.clock.Now()
+					if last.IsZero() {
+						last = now
+						continue
+					}
+					intervals = append(intervals, float64(now.Sub(last)))
+					last = now
+					if len(intervals) > window {
+						intervals = intervals[1:]
+					}
+
+					out.Publish(time.Duration(stdDev(intervals)))
+				}
+			}
+		}()
+	})
+}
+
+// stdDev returns the population standard deviation of list
+func stdDev(list []float64) float64 {
+	mean := 0.0
+	for _, v := range list {
+		mean += v
+	}
+	mean /= float64(len(list))
+
+	variance := 0.0
+	for _, v := range list {
+		variance += (v - mean) * (v - mean)
+	}
+
+	return math.Sqrt(variance / float64(len(list)))
+}
+
+// Pace emits all the events of ob in order, but delays them so that consecutive emissions are at least d apart.
+// The delayed events are buffered without a limit, so the buffer keeps growing while ob emits faster than one
+// event per d. When ob completes the buffered events are still emitted before the returned observable completes.
+func (ob *Observable) Pace(ctx context.Context, d time.Duration) *Observable {
+	return ob.derive(func(out *Observable) {
+		s := ob.follow()
+
+		go func() {
+			defer trackGoroutine()()
+			defer ob.Unsubscribe(s)
+
+			// timer is nil once d passed since the last emission
+			var timer <-chan time.Time
+			queue := []Event{}
+			events := s
+
+			for {
+				if events == nil && len(queue) == 0 {
+					out.CloseDrain()
+					return
+				}
+
+				select {
+				case <-ctx.Done():
+					out.Close()
+					return
+
+				case e, ok := <-events:
+					if !ok {
+						events = nil
+						continue
+					}
+					if timer == nil {
+						out.Publish(e)
+						timer = ob.clock.After(d)
+						continue
+					}
+					queue = append(queue, e)
+
+				case <-timer:
+					timer = nil
+					if len(queue) > 0 {
+						out.Publish(queue[0])
+						queue[0] = nil
+						queue = queue[1:]
+						timer = ob.clock.After(d)
+					}
+				}
+			}
+		}()
+	})
+}
+
+// TopK emits, at the end of each window, the k events of ob with the highest score received during the window,
+// sorted by score in descending order as a []Event, the events of the same score keep their order.
+// A window without events emits nothing. When ob completes the top of the current window is emitted.
+// A k below 1 is treated as 1, a window <= 0 is treated as 1 second.
+func (ob *Observable) TopK(ctx context.Context, window time.Duration, k int, score func(Event) float64) *Observable {
+	if k < 1 {
+		k = 1
+	}
+	if window <= 0 {
+		window = time.Second
+	}
+
+	return ob.derive(func(out *Observable) {
+		s := ob.follow()
+		ticker := ob.clock.NewTicker(window)
+		guard := supervise(ctx)
+
+		go func() {
+			defer trackGoroutine()()
+			defer guard(out)
+			defer ob.Unsubscribe(s)
+			defer ticker.Stop()
+
+			h := &scoredHeap{}
+			var seq uint64
+
+			flush := func() {
+				if h.Len() == 0 {
+					return
+				}
+				list := append([]scored{}, *h...)
+				sort.Slice(list, func(i, j int) bool { return list[j].less(list[i]) })
+				events := make([]Event, len(list))
+				for i, item := range list {
+					events[i] = item.e
+				}
+				out.Publish(events)
+				*h = (*h)[:0]
+			}
+
+			for {
+				select {
+				case <-ctx.Done():
+					out.Close()
+					return
+
+				case e, ok := <-s:
+					if !ok {
+						flush()
+						out.CloseDrain()
+						return
+					}
+					seq++
+					item := scored{e, score(e), seq}
+					if h.Len() < k {
+						heap.Push(h, item)
+					} else if (*h)[0].less(item) {
+						(*h)[0] = item
+						heap.Fix(h, 0)
+					}
+
+				case <-ticker.Chan():
+					flush()
+				}
+			}
+		}()
+	})
+}
+
+// scored event of TopK, seq is the arrival order
+type scored struct {
+	e     Event
+	score float64
+	seq   uint64
+}
+
+// less returns true if s ranks below o, a later event ranks below an earlier one of the same score
+func (s scored) less(o scored) bool {
+	if s.score == o.score {
+		return s.seq > o.seq
+	}
+	return s.score < o.score
+}
+
+// scoredHeap is a min-heap of scored events, the lowest ranked one is at the root
+type scoredHeap []scored
+
+func (h scoredHeap) Len() int            { return len(h) }
+func (h scoredHeap) Less(i, j int) bool  { return h[i].less(h[j]) }
+func (h scoredHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *scoredHeap) Push(x interface{}) { *h = append(*h, x.(scored)) }
+
+func (h *scoredHeap) Pop() interface{} {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
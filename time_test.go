@@ -0,0 +1,386 @@
+package goob_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ysmood/goob"
+)
+
+func TestThrottleTime(t *testing.T) {
+	checkLeak(t)
+
+	ctx := context.Background()
+
+	run := func(leading, trailing bool) []goob.Event {
+		ob := goob.New()
+		s := ob.ThrottleTime(ctx, 30*time.Millisecond, leading, trailing).Subscribe()
+
+		ob.Publish(1)
+		ob.Publish(2)
+		ob.Publish(3)
+		time.Sleep(100 * time.Millisecond)
+		ob.Publish(4)
+		ob.CloseDrain()
+
+		return collect(s)
+	}
+
+	eq(t, []goob.Event{1, 4}, run(true, false))
+	eq(t, []goob.Event{3, 4}, run(false, true))
+	eq(t, []goob.Event{1, 3, 4}, run(true, true))
+	eq(t, []goob.Event{}, run(false, false))
+}
+
+func TestThrottleLatest(t *testing.T) {
+	checkLeak(t)
+
+	ob := goob.New()
+	s := ob.ThrottleLatest(context.Background(), 30*time.Millisecond).Subscribe()
+
+	for i := 0; i < 10; i++ {
+		ob.Publish(i)
+	}
+	time.Sleep(100 * time.Millisecond)
+	for i := 10; i < 20; i++ {
+		ob.Publish(i)
+	}
+	time.Sleep(100 * time.Millisecond)
+	ob.CloseDrain()
+
+	eq(t, []goob.Event{0, 9, 10, 19}, collect(s))
+}
+
+func TestDebounce(t *testing.T) {
+	checkLeak(t)
+
+	clock := goob.NewTestClock()
+	ob := goob.New(goob.WithClock(clock))
+	s := ob.Debounce(context.Background(), 10*time.Millisecond).Subscribe()
+
+	ob.Publish(1)
+	clock.BlockUntil(1)
+	clock.Advance(5 * time.Millisecond)
+	ob.Publish(2)
+	clock.BlockUntil(2) // the superseded timer of 1 still counts until it's due
+	clock.Advance(10 * time.Millisecond)
+	eq(t, 2, <-s)
+
+	ob.Publish(3)
+	clock.BlockUntil(1)
+	clock.Advance(10 * time.Millisecond)
+	eq(t, 3, <-s)
+
+	ob.Publish(4)
+	ob.CloseDrain()
+	eq(t, []goob.Event{4}, collect(s))
+}
+
+func TestOperatorsInheritClock(t *testing.T) {
+	checkLeak(t)
+
+	clock := goob.NewTestClock()
+	ob := goob.New(goob.WithClock(clock))
+	s := ob.Map(context.Background(), func(e goob.Event) goob.Event { return e }).
+		Debounce(context.Background(), time.Hour).Subscribe()
+
+	ob.Publish(1)
+	clock.BlockUntil(1)
+	clock.Advance(time.Hour)
+	eq(t, 1, <-s)
+	ob.Close()
+}
+
+func TestRate(t *testing.T) {
+	checkLeak(t)
+
+	clock := goob.NewTestClock()
+	ob := goob.New(goob.WithClock(clock))
+	s := ob.Rate(context.Background(), 2*time.Second).Subscribe()
+	clock.BlockUntil(1)
+
+	// a steady rate of 5 events per second
+	for i := 0; i < 10; i++ {
+		ob.Publish(i)
+	}
+	time.Sleep(10 * time.Millisecond)
+	clock.Advance(2 * time.Second)
+	eq(t, 5.0, <-s)
+
+	ob.Publish(1)
+	time.Sleep(10 * time.Millisecond)
+	clock.Advance(2 * time.Second)
+	eq(t, 0.5, <-s)
+
+	ob.CloseDrain()
+	eq(t, []goob.Event{}, collect(s))
+}
+
+func TestDebounceAdaptive(t *testing.T) {
+	checkLeak(t)
+
+	clock := goob.NewTestClock()
+	ob := goob.New(goob.WithClock(clock))
+	s := ob.DebounceAdaptive(context.Background(), 10*time.Millisecond, 100*time.Millisecond).Subscribe()
+
+	// a sparse event uses the min delay
+	ob.Publish(1)
+	clock.BlockUntil(1)
+	clock.Advance(10 * time.Millisecond)
+	eq(t, 1, <-s)
+
+	// a rapid burst lengthens the delay to almost max
+	clock.Advance(time.Second)
+	ob.Publish(2)
+	clock.BlockUntil(1)
+	clock.Advance(time.Millisecond)
+	ob.Publish(3)
+	clock.BlockUntil(2) // the superseded timer of 2 still counts until it's due
+	clock.Advance(20 * time.Millisecond)
+
+	select {
+	case e := <-s:
+		t.Fatal("emitted too early", e)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	clock.Advance(80 * time.Millisecond)
+	eq(t, 3, <-s)
+
+	// it relaxes back to min when calm
+	clock.Advance(time.Second)
+	ob.Publish(4)
+	clock.BlockUntil(1)
+	clock.Advance(10 * time.Millisecond)
+	eq(t, 4, <-s)
+
+	ob.CloseDrain()
+	eq(t, []goob.Event{}, collect(s))
+}
+
+func TestCoalesceSignal(t *testing.T) {
+	checkLeak(t)
+
+	clock := goob.NewTestClock()
+	ob := goob.New(goob.WithClock(clock))
+	s := ob.CoalesceSignal(context.Background(), 10*time.Millisecond).Subscribe()
+
+	for i := 0; i < 10; i++ {
+		ob.Publish(i)
+	}
+	clock.BlockUntil(1)
+	settle()
+	clock.Advance(10 * time.Millisecond)
+	eq(t, struct{}{}, <-s)
+
+	ob.Publish(10)
+	clock.BlockUntil(1)
+	ob.CloseDrain()
+	eq(t, []goob.Event{struct{}{}}, collect(s))
+}
+
+func TestHeartbeat(t *testing.T) {
+	checkLeak(t)
+
+	clock := goob.NewTestClock()
+	ob := goob.New(goob.WithClock(clock))
+	s := ob.Heartbeat(context.Background(), 10*time.Millisecond, "beat").Subscribe()
+
+	clock.BlockUntil(1)
+	clock.Advance(10 * time.Millisecond)
+	eq(t, "beat", <-s)
+	clock.BlockUntil(1)
+	clock.Advance(10 * time.Millisecond)
+	eq(t, "beat", <-s)
+
+	clock.BlockUntil(1)
+	clock.Advance(5 * time.Millisecond)
+	ob.Publish(1)
+	eq(t, 1, <-s)
+
+	clock.BlockUntil(2) // the timer before the event still counts until it's due
+	clock.Advance(5 * time.Millisecond)
+	settle()
+	select {
+	case e := <-s:
+		t.Fatal("the event should reset the timer", e)
+	default:
+	}
+
+	clock.Advance(5 * time.Millisecond)
+	eq(t, "beat", <-s)
+
+	ob.CloseDrain()
+	eq(t, []goob.Event{}, collect(s))
+}
+
+func TestJitter(t *testing.T) {
+	checkLeak(t)
+
+	measure := func(intervals ...time.Duration) []goob.Event {
+		clock := goob.NewTestClock()
+		ob := goob.New(goob.WithClock(clock))
+		s := ob.Jitter(context.Background(), 2).Subscribe()
+
+		ob.Publish(0)
+		settle()
+
+		list := []goob.Event{}
+		for _, d := range intervals {
+			clock.Advance(d)
+			ob.Publish(0)
+			list = append(list, <-s)
+		}
+
+		ob.CloseDrain()
+		collect(s)
+		return list
+	}
+
+	ms := time.Millisecond
+
+	eq(t, []goob.Event{time.Duration(0), time.Duration(0), time.Duration(0), time.Duration(0), time.Duration(0)},
+		measure(10*ms, 10*ms, 10*ms, 10*ms, 10*ms))
+
+	eq(t, []goob.Event{time.Duration(0), 10 * ms, 10 * ms, 10 * ms, 10 * ms},
+		measure(10*ms, 30*ms, 10*ms, 30*ms, 10*ms))
+}
+
+func TestPace(t *testing.T) {
+	checkLeak(t)
+
+	clock := goob.NewTestClock()
+	ob := goob.New(goob.WithClock(clock))
+	s := ob.Pace(context.Background(), 10*time.Millisecond).Subscribe()
+
+	ob.Publish(1)
+	ob.Publish(2)
+	ob.Publish(3)
+	eq(t, 1, <-s)
+	settle()
+
+	clock.BlockUntil(1)
+	clock.Advance(10 * time.Millisecond)
+	eq(t, 2, <-s)
+
+	clock.BlockUntil(1)
+	clock.Advance(9 * time.Millisecond)
+	settle()
+	select {
+	case e := <-s:
+		t.Fatal("emitted too early", e)
+	default:
+	}
+
+	ob.CloseDrain()
+	clock.Advance(time.Millisecond)
+	eq(t, []goob.Event{3}, collect(s))
+}
+
+func TestDebounceDistinct(t *testing.T) {
+	checkLeak(t)
+
+	clock := goob.NewTestClock()
+	ob := goob.New(goob.WithClock(clock))
+	s := ob.DebounceDistinct(context.Background(), 10*time.Millisecond, nil).Subscribe()
+
+	ob.Publish("a")
+	clock.BlockUntil(1)
+	clock.Advance(10 * time.Millisecond)
+	eq(t, "a", <-s)
+
+	ob.Publish("a")
+	ob.Publish("a")
+	ob.Publish("a")
+	settle()
+	clock.BlockUntil(3)
+	clock.Advance(10 * time.Millisecond)
+	settle()
+	select {
+	case e := <-s:
+		t.Fatal("the same value should not be emitted", e)
+	default:
+	}
+
+	ob.Publish("b")
+	clock.BlockUntil(1)
+	clock.Advance(10 * time.Millisecond)
+	eq(t, "b", <-s)
+
+	ob.Publish("a")
+	ob.Publish("b")
+	ob.CloseDrain()
+	eq(t, []goob.Event{}, collect(s))
+}
+
+func TestTopK(t *testing.T) {
+	checkLeak(t)
+
+	type player struct {
+		name  string
+		score float64
+	}
+	score := func(e goob.Event) float64 { return e.(player).score }
+
+	clock := goob.NewTestClock()
+	ob := goob.New(goob.WithClock(clock))
+	s := ob.TopK(context.Background(), time.Second, 2, score).Subscribe()
+	clock.BlockUntil(1)
+
+	ob.Publish(player{"a", 3})
+	ob.Publish(player{"b", 9})
+	ob.Publish(player{"c", 5})
+	ob.Publish(player{"d", 1})
+	settle()
+	clock.Advance(time.Second)
+	eq(t, []goob.Event{player{"b", 9}, player{"c", 5}}, <-s)
+
+	ob.Publish(player{"e", 2})
+	ob.Publish(player{"f", 7})
+	ob.Publish(player{"g", 7})
+	settle()
+	clock.Advance(time.Second)
+	eq(t, []goob.Event{player{"f", 7}, player{"g", 7}}, <-s)
+
+	ob.Publish(player{"h", 4})
+	ob.CloseDrain()
+	eq(t, []goob.Event{[]goob.Event{player{"h", 4}}}, collect(s))
+}
+
+func TestRateNonPositiveWindow(t *testing.T) {
+	checkLeak(t)
+
+	clock := goob.NewTestClock()
+	ob := goob.New(goob.WithClock(clock))
+	s := ob.Rate(context.Background(), 0).Subscribe()
+	clock.BlockUntil(1)
+
+	ob.Publish(1)
+	ob.Publish(2)
+	settle()
+	clock.Advance(time.Second)
+	eq(t, 2.0, <-s)
+
+	ob.CloseDrain()
+	collect(s)
+}
+
+func TestTopKNonPositive(t *testing.T) {
+	checkLeak(t)
+
+	clock := goob.NewTestClock()
+	ob := goob.New(goob.WithClock(clock))
+	s := ob.TopK(context.Background(), -time.Minute, 0, func(e goob.Event) float64 { return float64(e.(int)) }).Subscribe()
+	clock.BlockUntil(1)
+
+	ob.Publish(1)
+	ob.Publish(3)
+	ob.Publish(2)
+	settle()
+	clock.Advance(time.Second)
+	eq(t, []goob.Event{3}, <-s)
+
+	ob.CloseDrain()
+	collect(s)
+}